@@ -3,16 +3,67 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	MongoURI       string
-	DatabaseName   string
-	ServerPort     string
-	AllowedOrigins string
-	JWTSecret      string
+	MongoURI               string
+	DatabaseName           string
+	ServerPort             string
+	AllowedOrigins         string
+	TrustedProxies         []string
+	JWTIssuer              string
+	JWTAudience            string
+	JWTSigningAlg          string
+	JWTKeyRotationInterval time.Duration
+	JWTKeyGracePeriod      time.Duration
+	JWTKeyEncryptionSecret string
+	AccessTokenTTL         time.Duration
+	RefreshTokenTTL        time.Duration
+	AdminUsername          string
+	AdminPassword          string
+
+	LoginMaxFailures      int
+	LoginMaxFailuresPerIP int
+	LoginLockoutBase      time.Duration
+	LoginLockoutMax       time.Duration
+	LoginFailureWindow    time.Duration
+
+	// SSO lewat OpenID Connect, aktif kalau OIDC_ISSUER_URL diisi.
+	OIDCProviderName    string
+	OIDCIssuerURL       string
+	OIDCClientID        string
+	OIDCClientSecret    string
+	OIDCRedirectURL     string
+	OIDCScopes          []string
+	OIDCUsernameClaim   string
+	OIDCGroupsClaim     string
+	OIDCRegionClaim     string
+	OIDCCabangClaim     string
+	OIDCRoleMapping     map[string]string
+	OIDCDefaultRole     string
+	OIDCAutoCreateUsers bool
+	OIDCLinkByUsername  bool
+
+	// Alias header tambahan untuk import Excel, format "Alias=Nama Kolom,...".
+	ColorisColumnAliases  map[string]string
+	TrainingColumnAliases map[string]string
+	SelloutColumnAliases  map[string]string
+
+	// Batas ukuran body request upload import, dalam byte.
+	ImportMaxUploadSize int64
+
+	// Natural key (field BSON) untuk upsert import dan field periode untuk mode replace.
+	ColorisImportKey     []string
+	ColorisImportPeriod  []string
+	TrainingImportKey    []string
+	TrainingImportPeriod []string
+	SelloutImportKey     []string
+	SelloutImportPeriod  []string
 }
 
 func LoadConfig() *Config {
@@ -20,11 +71,63 @@ func LoadConfig() *Config {
 	_ = godotenv.Load()
 
 	config := &Config{
-		MongoURI:       mustEnv("MONGO_URI"),
-		DatabaseName:   getEnv("DATABASE_NAME", "dashboard_db"),
-		ServerPort:     getEnv("SERVER_PORT", "8080"),
-		AllowedOrigins: getEnv("ALLOWED_ORIGINS", "*"),
-		JWTSecret:      mustEnv("JWT_SECRET"),
+		MongoURI:               mustEnv("MONGO_URI"),
+		DatabaseName:           getEnv("DATABASE_NAME", "dashboard_db"),
+		ServerPort:             getEnv("SERVER_PORT", "8080"),
+		AllowedOrigins:         getEnv("ALLOWED_ORIGINS", "*"),
+		TrustedProxies:         getList("TRUSTED_PROXIES", nil),
+		JWTIssuer:              getEnv("JWT_ISSUER", "dashboard-backend"),
+		JWTAudience:            getEnv("JWT_AUDIENCE", "dashboard-api"),
+		JWTSigningAlg:          getEnv("JWT_SIGNING_ALG", "RS256"),
+		JWTKeyRotationInterval: getDuration("JWT_KEY_ROTATION_INTERVAL", 30*24*time.Hour),
+		JWTKeyGracePeriod:      getDuration("JWT_KEY_GRACE_PERIOD", time.Hour),
+		JWTKeyEncryptionSecret: getEnv("JWT_KEY_ENCRYPTION_SECRET", ""),
+		AccessTokenTTL:         getDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:        getDuration("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+		AdminUsername:          getEnv("ADMIN_USERNAME", "admin"),
+		AdminPassword:          getEnv("ADMIN_PASSWORD", ""),
+
+		LoginMaxFailures:      getInt("LOGIN_MAX_FAILURES", 5),
+		LoginMaxFailuresPerIP: getInt("LOGIN_MAX_FAILURES_PER_IP", 20),
+		LoginLockoutBase:      getDuration("LOGIN_LOCKOUT_BASE", time.Minute),
+		LoginLockoutMax:       getDuration("LOGIN_LOCKOUT_MAX", time.Hour),
+		LoginFailureWindow:    getDuration("LOGIN_FAILURE_WINDOW", 24*time.Hour),
+
+		OIDCProviderName:    getEnv("OIDC_PROVIDER_NAME", "oidc"),
+		OIDCIssuerURL:       getEnv("OIDC_ISSUER_URL", ""),
+		OIDCClientID:        getEnv("OIDC_CLIENT_ID", ""),
+		OIDCClientSecret:    getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:     getEnv("OIDC_REDIRECT_URL", ""),
+		OIDCScopes:          getList("OIDC_SCOPES", []string{"openid", "profile", "email"}),
+		OIDCUsernameClaim:   getEnv("OIDC_USERNAME_CLAIM", "preferred_username"),
+		OIDCGroupsClaim:     getEnv("OIDC_GROUPS_CLAIM", "groups"),
+		OIDCRegionClaim:     getEnv("OIDC_REGION_CLAIM", ""),
+		OIDCCabangClaim:     getEnv("OIDC_CABANG_CLAIM", ""),
+		OIDCRoleMapping:     getMap("OIDC_ROLE_MAPPING"),
+		OIDCDefaultRole:     getEnv("OIDC_DEFAULT_ROLE", "viewer"),
+		OIDCAutoCreateUsers: getBool("OIDC_AUTO_CREATE_USERS", false),
+		OIDCLinkByUsername:  getBool("OIDC_LINK_BY_USERNAME", false),
+
+		ColorisColumnAliases:  getMap("IMPORT_COLUMN_ALIASES_COLORIS"),
+		TrainingColumnAliases: getMap("IMPORT_COLUMN_ALIASES_TRAINING"),
+		SelloutColumnAliases:  getMap("IMPORT_COLUMN_ALIASES_SELLOUT"),
+
+		ImportMaxUploadSize: int64(getInt("IMPORT_MAX_UPLOAD_MB", 32)) << 20,
+
+		ColorisImportKey:     getList("IMPORT_KEY_COLORIS", []string{"timestamp", "nama_lengkap_sesuai_ktp", "materi"}),
+		ColorisImportPeriod:  getList("IMPORT_PERIOD_COLORIS", []string{"bulan"}),
+		TrainingImportKey:    getList("IMPORT_KEY_TRAINING", []string{"timestamp", "nama_lengkap_sesuai_ktp", "materi_pelatihan"}),
+		TrainingImportPeriod: getList("IMPORT_PERIOD_TRAINING", []string{"bulan"}),
+		SelloutImportKey:     getList("IMPORT_KEY_SELLOUT", []string{"tahun", "bulan", "no_reg", "outlet"}),
+		SelloutImportPeriod:  getList("IMPORT_PERIOD_SELLOUT", []string{"tahun", "bulan"}),
+	}
+
+	if config.OIDCIssuerURL != "" && (config.OIDCClientID == "" || config.OIDCRedirectURL == "") {
+		log.Fatalf("OIDC_CLIENT_ID and OIDC_REDIRECT_URL are required when OIDC_ISSUER_URL is set")
+	}
+
+	if config.JWTKeyGracePeriod < config.AccessTokenTTL {
+		log.Printf("Warning: JWT_KEY_GRACE_PERIOD (%s) is shorter than ACCESS_TOKEN_TTL (%s), tokens signed by a rotated key may be rejected early", config.JWTKeyGracePeriod, config.AccessTokenTTL)
 	}
 
 	return config
@@ -46,3 +149,71 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Fatalf("Invalid duration for %s: %v", key, err)
+	}
+	return d
+}
+
+func getInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("Invalid integer for %s: %v", key, err)
+	}
+	return n
+}
+
+func getBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("Invalid boolean for %s: %v", key, err)
+	}
+	return b
+}
+
+// getList membaca daftar yang dipisah koma, mis. "openid,profile,email".
+func getList(key string, defaultValue []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getMap membaca pasangan key=value yang dipisah koma, mis. "dashboard-admin=admin,sales=viewer".
+func getMap(key string) map[string]string {
+	m := map[string]string{}
+	for _, pair := range getList(key, nil) {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			log.Fatalf("Invalid %s entry %q, expected key=value", key, pair)
+		}
+		m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return m
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+)
+
+type contextKey string
+
+const (
+	scopeKey       contextKey = "data_scope"
+	principalKey   contextKey = "principal"
+	requestInfoKey contextKey = "request_info"
+)
+
+// RequestInfo berisi metadata request yang ikut dicatat di audit log.
+type RequestInfo struct {
+	RequestID string
+	IP        string
+	UserAgent string
+}
+
+// WithScope menyimpan data scope user ke context request supaya bisa dibaca
+// oleh service dan repository tanpa bergantung pada gin.
+func WithScope(ctx context.Context, scope models.DataScope) context.Context {
+	return context.WithValue(ctx, scopeKey, scope)
+}
+
+// ScopeFromContext mengembalikan data scope dari context. Context tanpa scope
+// dianggap tidak dibatasi.
+func ScopeFromContext(ctx context.Context) models.DataScope {
+	scope, _ := ctx.Value(scopeKey).(models.DataScope)
+	return scope
+}
+
+// WithPrincipal menyimpan identitas user yang sedang login ke context request.
+func WithPrincipal(ctx context.Context, principal *models.Principal) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+// PrincipalFromContext mengembalikan nil kalau request tidak terautentikasi.
+func PrincipalFromContext(ctx context.Context) *models.Principal {
+	principal, _ := ctx.Value(principalKey).(*models.Principal)
+	return principal
+}
+
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey, info)
+}
+
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey).(RequestInfo)
+	return info
+}
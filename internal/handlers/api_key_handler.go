@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/service"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type APIKeyHandler struct {
+	service service.APIKeyService
+}
+
+func NewAPIKeyHandler(service service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+	}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	// API key tidak boleh dipakai untuk menerbitkan API key baru.
+	if c.GetString("auth_method") == models.AuthMethodAPIKey {
+		c.JSON(http.StatusForbidden, gin.H{"error": "API key tidak bisa dipakai untuk membuat API key baru"})
+		return
+	}
+
+	var req models.APIKeyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.service.CreateAPIKey(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrAPIKeyPermissionInvalid) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key berhasil dibuat, simpan key ini karena tidak akan ditampilkan lagi",
+		"data":    response,
+	})
+}
+
+func (h *APIKeyHandler) GetAPIKeys(c *gin.Context) {
+	apiKeys, err := h.service.GetAPIKeys(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": apiKeys})
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	err := h.service.RevokeAPIKey(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API key tidak ditemukan"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key berhasil dicabut"})
+}
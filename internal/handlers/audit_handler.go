@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/service"
+)
+
+type AuditHandler struct {
+	service service.AuditService
+}
+
+func NewAuditHandler(service service.AuditService) *AuditHandler {
+	return &AuditHandler{
+		service: service,
+	}
+}
+
+// GetAuditLogs mendukung filter entity, entity_id, action, user (username atau user ID),
+// from dan to. Tanggal boleh RFC3339 atau YYYY-MM-DD; "to" berupa tanggal ikut dihitung sampai akhir hari.
+func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	filter := models.AuditLogFilter{
+		Entity:   c.Query("entity"),
+		EntityID: c.Query("entity_id"),
+		Action:   c.Query("action"),
+		User:     c.Query("user"),
+	}
+
+	if value := c.Query("from"); value != "" {
+		from, _, err := parseAuditDate(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from date, use YYYY-MM-DD or RFC3339"})
+			return
+		}
+		filter.From = &from
+	}
+
+	if value := c.Query("to"); value != "" {
+		to, dateOnly, err := parseAuditDate(value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to date, use YYYY-MM-DD or RFC3339"})
+			return
+		}
+		if dateOnly {
+			to = to.AddDate(0, 0, 1)
+		}
+		filter.To = &to
+	}
+
+	response, err := h.service.GetAuditLogs(c.Request.Context(), filter, page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func parseAuditDate(value string) (time.Time, bool, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, false, nil
+	}
+
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	return t, true, err
+}
@@ -1,7 +1,11 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/web-dashboard-made-by-renz/backend/internal/models"
@@ -25,9 +29,29 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response, err := h.service.Login(req.Username, req.Password)
+	client := models.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+
+	response, err := h.service.Login(c.Request.Context(), req.Username, req.Password, client)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		var lockout *service.LockoutError
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid username or password"})
+		case errors.Is(err, service.ErrAccountDisabled):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+		case errors.As(err, &lockout):
+			retryAfter := int(math.Ceil(time.Until(lockout.Until).Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":       "Too many failed login attempts, please try again later",
+				"retry_after": retryAfter,
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -37,21 +61,78 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	})
 }
 
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
+		return
+	}
+
+	response, err := h.service.Refresh(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRefreshToken):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired refresh token"})
+		case errors.Is(err, service.ErrAccountDisabled):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Token refreshed",
+		"data":    response,
+	})
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if c.GetString("session_id") == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Logout requires a session token"})
+		return
+	}
+
+	expiresAt, _ := c.Get("token_expires_at")
+	tokenExpiresAt, ok := expiresAt.(time.Time)
+	if !ok {
+		tokenExpiresAt = time.Now().Add(24 * time.Hour)
+	}
+
+	err := h.service.Logout(c.Request.Context(), c.GetString("session_id"), c.GetString("jti"), tokenExpiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
+}
+
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	count, err := h.service.LogoutAll(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "All sessions logged out",
+		"count":   count,
+	})
+}
+
+// Verify mengembalikan profil, scope dan permission dari identitas yang sudah
+// divalidasi AuthMiddleware (access token maupun API key).
 func (h *AuthHandler) Verify(c *gin.Context) {
-	// Get user info from context (set by middleware)
-	username, exists := c.Get("username")
-	if !exists {
+	value, _ := c.Get("principal")
+	principal, ok := value.(*models.Principal)
+	if !ok {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
 		return
 	}
 
-	role, _ := c.Get("role")
-
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Token is valid",
-		"user": models.User{
-			Username: username.(string),
-			Role:     role.(string),
-		},
+		"user":    principal,
 	})
 }
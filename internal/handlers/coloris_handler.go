@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
 
@@ -12,11 +11,13 @@ import (
 
 type ColorisHandler struct {
 	service service.ColorisService
+	jobs    service.ImportJobService
 }
 
-func NewColorisHandler(service service.ColorisService) *ColorisHandler {
+func NewColorisHandler(service service.ColorisService, jobs service.ImportJobService) *ColorisHandler {
 	return &ColorisHandler{
 		service: service,
+		jobs:    jobs,
 	}
 }
 
@@ -29,7 +30,7 @@ func (h *ColorisHandler) CreateColoris(c *gin.Context) {
 
 	err := h.service.CreateColoris(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondDataError(c, err)
 		return
 	}
 
@@ -52,32 +53,9 @@ func (h *ColorisHandler) GetAllColoris(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
 
-	region := c.Query("region")
-	cabang := c.Query("cabang")
-	bulan := c.Query("bulan")
-
-	filters := make(map[string]string)
-	if region != "" {
-		filters["region"] = region
-	}
-	if cabang != "" {
-		filters["cabang"] = cabang
-	}
-	if bulan != "" {
-		filters["bulan"] = bulan
-	}
-
-	var response *models.ColorisListResponse
-	var err error
-
-	if len(filters) > 0 {
-		response, err = h.service.GetColorisWithFilters(c.Request.Context(), filters, page, perPage)
-	} else {
-		response, err = h.service.GetAllColoris(c.Request.Context(), page, perPage)
-	}
-
+	response, err := h.service.GetColorisWithFilters(c.Request.Context(), dataQuery(c), page, perPage)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondDataError(c, err)
 		return
 	}
 
@@ -95,7 +73,7 @@ func (h *ColorisHandler) UpdateColoris(c *gin.Context) {
 
 	err := h.service.UpdateColoris(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondDataError(c, err)
 		return
 	}
 
@@ -107,7 +85,7 @@ func (h *ColorisHandler) DeleteColoris(c *gin.Context) {
 
 	err := h.service.DeleteColoris(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondDataError(c, err)
 		return
 	}
 
@@ -115,51 +93,17 @@ func (h *ColorisHandler) DeleteColoris(c *gin.Context) {
 }
 
 func (h *ColorisHandler) ImportExcel(c *gin.Context) {
-	file, err := c.FormFile("file")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak ditemukan"})
-		return
-	}
-
-	if file.Header.Get("Content-Type") != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" &&
-		file.Header.Get("Content-Type") != "application/vnd.ms-excel" {
-		ext := file.Filename[len(file.Filename)-5:]
-		if ext != ".xlsx" && ext != ".xls" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "File harus berformat Excel (.xlsx atau .xls)"})
-			return
-		}
-	}
+	importExcel(c, models.AuditEntityColoris, h.service, h.jobs)
+}
 
-	count, err := h.service.ImportFromExcel(c.Request.Context(), file)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
+func (h *ColorisHandler) ImportSheets(c *gin.Context) {
+	importSheets(c, h.service)
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Import berhasil",
-		"count":   count,
-	})
+func (h *ColorisHandler) DownloadTemplate(c *gin.Context) {
+	importTemplate(c, h.service, "coloris_template.xlsx")
 }
 
 func (h *ColorisHandler) ExportExcel(c *gin.Context) {
-	excelFile, err := h.service.ExportToExcel(c.Request.Context())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	defer excelFile.Close()
-
-	filename := fmt.Sprintf("coloris_data_%s.xlsx", c.Query("filename"))
-	if c.Query("filename") == "" {
-		filename = "coloris_data.xlsx"
-	}
-
-	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-
-	if err := excelFile.Write(c.Writer); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menulis file Excel"})
-		return
-	}
+	exportData(c, h.service, "coloris")
 }
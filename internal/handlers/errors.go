@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web-dashboard-made-by-renz/backend/internal/service"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// respondDataError memetakan error dari service Coloris, Training, dan Sellout ke status HTTP.
+func respondDataError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Data tidak ditemukan"})
+	case errors.Is(err, service.ErrOutOfScope):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrInvalidQuery):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case mongo.IsDuplicateKeyError(err):
+		c.JSON(http.StatusConflict, gin.H{"error": "Data dengan kunci yang sama sudah ada"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web-dashboard-made-by-renz/backend/internal/service"
+	"github.com/web-dashboard-made-by-renz/backend/pkg/utils"
+	"github.com/xuri/excelize/v2"
+)
+
+// exportData mengirim export sesuai ?format=: xlsx (bawaan), report, csv, ndjson, atau json.
+// entity dipakai sebagai awalan nama file, mis. "sellout" menjadi sellout_data.xlsx.
+func exportData(c *gin.Context, exporter service.Exporter, entity string) {
+	name := entity + "_data"
+	format := strings.ToLower(strings.TrimSpace(c.DefaultQuery("format", "xlsx")))
+	switch format {
+	case "xlsx", "excel":
+		exportExcel(c, exporter.ExportToExcel, name)
+		return
+	case "report":
+		reporter, ok := exporter.(service.ReportExporter)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Format report hanya tersedia untuk data sellout"})
+			return
+		}
+		exportExcel(c, reporter.ExportReport, entity+"_report")
+		return
+	case string(utils.ExportCSV), string(utils.ExportNDJSON), string(utils.ExportJSON):
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Format export harus xlsx, report, csv, ndjson, atau json"})
+		return
+	}
+
+	opts, err := recordOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	exportFormat := utils.ExportFormat(format)
+	filename := exportFilename(c, name, format)
+	err = exporter.ExportRecords(c.Request.Context(), dataQuery(c), queryList(c, "columns"), func(columns *utils.ExportColumns) (utils.RecordWriter, error) {
+		c.Header("Content-Type", exportFormat.ContentType())
+		c.Header("Content-Disposition", contentDisposition("attachment", filename))
+		c.Status(http.StatusOK)
+		return utils.NewRecordWriter(c.Writer, exportFormat, columns, opts)
+	})
+	if err == nil {
+		return
+	}
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		respondDataError(c, err)
+		return
+	}
+
+	// Sebagian data sudah terkirim. Koneksi diputus tanpa penutup chunk supaya klien tahu
+	// file tidak lengkap, bukan menerima file yang terpotong tanpa tanda.
+	log.Printf("Warning: %s export failed after streaming started: %v", name, err)
+	if conn, _, hijackErr := c.Writer.Hijack(); hijackErr == nil {
+		conn.Close()
+	}
+	c.Abort()
+}
+
+// exportExcel mengirim file Excel yang dibuat oleh export (export biasa atau laporan).
+func exportExcel(c *gin.Context, export func(context.Context, service.DataQuery, []string) (*excelize.File, error), name string) {
+	excelFile, err := export(c.Request.Context(), dataQuery(c), queryList(c, "columns"))
+	if err != nil {
+		respondDataError(c, err)
+		return
+	}
+	defer excelFile.Close()
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", contentDisposition("attachment", exportFilename(c, name, "xlsx")))
+
+	if err := excelFile.Write(c.Writer); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menulis file Excel"})
+		return
+	}
+}
+
+// exportFilename membuat nama file export, dengan akhiran dari ?filename= kalau ada.
+func exportFilename(c *gin.Context, name, extension string) string {
+	if suffix := c.Query("filename"); suffix != "" {
+		return fmt.Sprintf("%s_%s.%s", name, suffix, extension)
+	}
+	return fmt.Sprintf("%s.%s", name, extension)
+}
+
+// contentDisposition membuat header Content-Disposition. Nama file di-quote atau di-encode
+// (RFC 2231) oleh mime.FormatMediaType, jadi ?filename= tidak bisa menyisipkan parameter lain.
+func contentDisposition(disposition, filename string) string {
+	return mime.FormatMediaType(disposition, map[string]string{"filename": filename})
+}
+
+// recordOptions membaca ?delimiter= (comma, semicolon, tab, pipe, atau satu karakter) dan
+// ?decimal= (dot atau comma) untuk export CSV.
+func recordOptions(c *gin.Context) (utils.RecordOptions, error) {
+	opts := utils.RecordOptions{Delimiter: ','}
+
+	switch delimiter := c.Query("delimiter"); strings.ToLower(delimiter) {
+	case "", "comma", ",":
+	case "semicolon", ";":
+		opts.Delimiter = ';'
+	case "tab", "\t", `\t`:
+		opts.Delimiter = '\t'
+	case "pipe", "|":
+		opts.Delimiter = '|'
+	default:
+		runes := []rune(delimiter)
+		if len(runes) != 1 || strings.ContainsRune("\"\r\n", runes[0]) {
+			return opts, errors.New("Delimiter tidak valid: gunakan comma, semicolon, tab, pipe, atau satu karakter")
+		}
+		opts.Delimiter = runes[0]
+	}
+
+	switch strings.ToLower(c.Query("decimal")) {
+	case "", "dot", ".":
+	case "comma", ",":
+		opts.DecimalComma = true
+	default:
+		return opts, errors.New("Decimal tidak valid: gunakan dot atau comma")
+	}
+	return opts, nil
+}
@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/service"
+	"github.com/web-dashboard-made-by-renz/backend/pkg/utils"
+)
+
+// importExcel memvalidasi file upload (Excel .xlsx/.xls, CSV, atau TSV) lalu menjalankan import. Dry run diproses langsung
+// dan laporannya dikirim di response; import sungguhan dijalankan sebagai job di background
+// dan statusnya dipantau lewat /import-jobs/:id.
+func importExcel(c *gin.Context, entity string, importer service.Importer, jobs service.ImportJobService) {
+	file, src, ok := openUpload(c)
+	if !ok {
+		return
+	}
+	defer src.Close()
+
+	opts, ok := importOptions(c)
+	if !ok {
+		return
+	}
+	opts.FileName = file.Filename
+
+	if opts.DryRun {
+		report, err := importer.ImportFromExcel(c.Request.Context(), src, opts)
+		respondImport(c, report, err)
+		return
+	}
+
+	// Sheet yang dipilih dicek dulu supaya salah nama sheet langsung dibalas, bukan
+	// menjadi job yang gagal.
+	if opts.Sheet != (utils.SheetSelection{}) {
+		_, sheets, err := utils.ListImportSheets(src, file.Filename)
+		if err == nil {
+			_, err = opts.Sheet.Resolve(sheets)
+		}
+		if err != nil {
+			respondFileError(c, err)
+			return
+		}
+	}
+
+	job, err := jobs.Submit(c.Request.Context(), entity, file, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "Import sedang diproses",
+		"data":    job,
+	})
+}
+
+// importSheets mengembalikan daftar sheet di file upload beserta hasil pengecekan
+// header-nya, supaya user bisa memilih sheet sebelum import.
+func importSheets(c *gin.Context, importer service.Importer) {
+	file, src, ok := openUpload(c)
+	if !ok {
+		return
+	}
+	defer src.Close()
+
+	workbook, err := importer.ImportSheets(c.Request.Context(), src, file.Filename)
+	if err != nil {
+		if errors.Is(err, utils.ErrUnsupportedFormat) || errors.Is(err, utils.ErrUnreadableFile) {
+			respondFileError(c, err)
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": workbook})
+}
+
+// importTemplate mengirim template .xlsx kosong untuk import, dengan header dan dropdown
+// sesuai data yang boleh diakses user.
+func importTemplate(c *gin.Context, importer service.Importer, filename string) {
+	file, err := importer.ImportTemplate(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+
+	if err := file.Write(c.Writer); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menulis file Excel"})
+		return
+	}
+}
+
+// openUpload membuka field "file" dan memastikan formatnya bisa diimport. Format dikenali
+// dari isi file, bukan dari Content-Type atau ekstensi yang dikirim client. Kalau gagal,
+// response 400 langsung dikirim dan ok bernilai false.
+func openUpload(c *gin.Context) (file *multipart.FileHeader, src multipart.File, ok bool) {
+	file, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Ukuran file melebihi batas %d MB", tooLarge.Limit>>20)})
+		return nil, nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak ditemukan"})
+		return nil, nil, false
+	}
+
+	src, err = file.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membuka file"})
+		return nil, nil, false
+	}
+
+	head := make([]byte, 4096)
+	n, _ := io.ReadFull(src, head)
+	if _, err := utils.DetectImportFormat(head[:n], file.Filename); err != nil {
+		src.Close()
+		respondFileError(c, err)
+		return nil, nil, false
+	}
+
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		src.Close()
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Gagal membuka file"})
+		return nil, nil, false
+	}
+	return file, src, true
+}
+
+// importOptions membaca ?dry_run=true (validasi tanpa menyimpan), ?mode=insert|upsert|replace,
+// ?decimal=dot|comma (format angka CSV/TSV), dan pilihan sheet: ?sheet=<nama>,
+// ?sheet_index=<n> (dimulai dari 0), atau ?all_sheets=true.
+// Kalau ada yang tidak valid, response 400 langsung dikirim dan ok bernilai false.
+func importOptions(c *gin.Context) (opts service.ImportOptions, ok bool) {
+	opts = service.ImportOptions{
+		DryRun: c.Query("dry_run") == "true",
+		Mode:   c.DefaultQuery("mode", models.ImportModeUpsert),
+		Sheet: utils.SheetSelection{
+			Name: c.Query("sheet"),
+			All:  c.Query("all_sheets") == "true",
+		},
+	}
+	if !models.IsValidImportMode(opts.Mode) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Mode import harus insert, upsert, atau replace"})
+		return opts, false
+	}
+
+	switch strings.ToLower(c.Query("decimal")) {
+	case "":
+	case "dot", ".":
+		opts.Numbers = utils.NumberFormatDot
+	case "comma", ",":
+		opts.Numbers = utils.NumberFormatComma
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Decimal tidak valid: gunakan dot atau comma"})
+		return opts, false
+	}
+
+	if value := c.Query("sheet_index"); value != "" {
+		index, err := strconv.Atoi(value)
+		if err != nil || index < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "sheet_index harus angka 0 atau lebih"})
+			return opts, false
+		}
+		opts.Sheet.Index = index
+	}
+
+	selected := 0
+	for _, set := range []bool{opts.Sheet.Name != "", c.Query("sheet_index") != "", opts.Sheet.All} {
+		if set {
+			selected++
+		}
+	}
+	if selected > 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Pilih salah satu: sheet, sheet_index, atau all_sheets"})
+		return opts, false
+	}
+	return opts, true
+}
+
+// respondImport mengirim hasil import beserta laporan per baris. Kalau import gagal
+// setelah file terbaca, laporan tetap dikirim supaya baris yang salah bisa diperbaiki.
+func respondImport(c *gin.Context, report *models.ImportReport, err error) {
+	if err != nil {
+		var missing *utils.MissingColumnsError
+		if errors.As(err, &missing) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"error":           "Kolom wajib tidak ditemukan di header file",
+				"missing_columns": missing.Columns,
+			})
+			return
+		}
+		if errors.Is(err, utils.ErrUnsupportedFormat) || errors.Is(err, utils.ErrUnreadableFile) || errors.Is(err, utils.ErrSheetNotFound) {
+			respondFileError(c, err)
+			return
+		}
+		if report == nil {
+			respondDataError(c, err)
+			return
+		}
+
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrOutOfScope):
+			status = http.StatusForbidden
+		case errors.Is(err, service.ErrNoValidImportRows):
+			status = http.StatusUnprocessableEntity
+		}
+		c.JSON(status, gin.H{"error": err.Error(), "report": report})
+		return
+	}
+
+	if report.DryRun {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Preview import, tidak ada data yang disimpan",
+			"report":  report,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Import berhasil",
+		"count":   report.Inserted + report.Updated,
+		"report":  report,
+	})
+}
+
+// respondFileError mengirim 400 untuk file yang formatnya tidak didukung, isinya rusak,
+// atau sheet yang dipilih tidak ada.
+func respondFileError(c *gin.Context, err error) {
+	var notFound *utils.SheetNotFoundError
+	if errors.As(err, &notFound) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":  "Sheet tidak ditemukan di file",
+			"detail": err.Error(),
+			"sheets": notFound.Sheets,
+		})
+		return
+	}
+
+	message := "File tidak bisa dibaca, pastikan file tidak rusak"
+	if errors.Is(err, utils.ErrUnsupportedFormat) {
+		message = "File harus berformat Excel (.xlsx atau .xls), CSV, atau TSV"
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": message, "detail": err.Error()})
+}
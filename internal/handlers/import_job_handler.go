@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/service"
+)
+
+type ImportJobHandler struct {
+	service service.ImportJobService
+}
+
+func NewImportJobHandler(service service.ImportJobService) *ImportJobHandler {
+	return &ImportJobHandler{
+		service: service,
+	}
+}
+
+// GetJobs mendukung filter entity dan status. Laporan per baris hanya ada di detail job.
+func (h *ImportJobHandler) GetJobs(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	filter := models.ImportJobFilter{
+		Entity: c.Query("entity"),
+		Status: c.Query("status"),
+	}
+
+	response, err := h.service.ListJobs(c.Request.Context(), filter, page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ImportJobHandler) GetJob(c *gin.Context) {
+	job, err := h.service.GetJob(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job import tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": job})
+}
+
+// CancelJob menghentikan job di batas batch berikutnya. Baris yang sudah tersimpan tetap ada.
+func (h *ImportJobHandler) CancelJob(c *gin.Context) {
+	job, err := h.service.Cancel(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrImportJobFinished) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Job import sudah selesai"})
+			return
+		}
+		c.JSON(http.StatusNotFound, gin.H{"error": "Job import tidak ditemukan"})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Pembatalan import diminta", "data": job})
+}
+
+// RollbackJob menghapus data dari batch import ini. Default-nya versi data sebelum import
+// juga dikembalikan; ?restore=false hanya menghapus data yang ditambah atau ditimpa import.
+func (h *ImportJobHandler) RollbackJob(c *gin.Context) {
+	restore := c.DefaultQuery("restore", "true") != "false"
+
+	job, err := h.service.Rollback(c.Request.Context(), c.Param("id"), restore)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrImportJobRunning):
+			c.JSON(http.StatusConflict, gin.H{"error": "Job import masih berjalan"})
+		case errors.Is(err, service.ErrImportRolledBack):
+			c.JSON(http.StatusConflict, gin.H{"error": "Import sudah di-rollback"})
+		case job != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "data": job})
+		default:
+			c.JSON(http.StatusNotFound, gin.H{"error": "Job import tidak ditemukan"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Rollback import berhasil", "data": job})
+}
@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web-dashboard-made-by-renz/backend/internal/service"
+)
+
+// dataQuery membaca filter, rentang tanggal (from/to), dan urutan (sort) dari query string.
+// List dan export memakai fungsi yang sama supaya isi file export sama dengan tampilan list.
+func dataQuery(c *gin.Context) service.DataQuery {
+	filters := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		if len(values) > 0 {
+			filters[key] = values[0]
+		}
+	}
+	return service.DataQuery{
+		Filters: filters,
+		From:    c.Query("from"),
+		To:      c.Query("to"),
+		Sort:    c.Query("sort"),
+	}
+}
+
+// queryList membaca parameter berisi daftar, baik "columns=a,b" maupun "columns=a&columns=b".
+func queryList(c *gin.Context, key string) []string {
+	var list []string
+	for _, value := range c.QueryArray(key) {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web-dashboard-made-by-renz/backend/internal/service"
+	"github.com/web-dashboard-made-by-renz/backend/pkg/utils"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+type ReportHandler struct {
+	service service.ReportService
+}
+
+func NewReportHandler(service service.ReportService) *ReportHandler {
+	return &ReportHandler{
+		service: service,
+	}
+}
+
+// MonthlyPDF mengirim laporan bulanan satu cabang dalam bentuk PDF.
+// Query: tahun, bulan (1-12), dan cabang, semuanya wajib.
+func (h *ReportHandler) MonthlyPDF(c *gin.Context) {
+	tahun, err := strconv.Atoi(c.Query("tahun"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tahun tidak valid"})
+		return
+	}
+	bulan, err := strconv.Atoi(c.Query("bulan"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Bulan tidak valid"})
+		return
+	}
+
+	report, err := h.service.MonthlyReport(c.Request.Context(), tahun, bulan, c.Query("cabang"))
+	if err != nil {
+		respondDataError(c, err)
+		return
+	}
+
+	// PDF dibuat di buffer dulu supaya error tidak menghasilkan file setengah jadi.
+	var buf bytes.Buffer
+	if err := utils.WriteMonthlyReportPDF(&buf, report); err != nil {
+		log.Printf("Warning: failed to render monthly report PDF: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal membuat file PDF"})
+		return
+	}
+
+	cabang := strings.Trim(unsafeFilenameChars.ReplaceAllString(strings.ToLower(report.Cabang), "_"), "_")
+	c.Header("Content-Disposition", contentDisposition("inline", fmt.Sprintf("laporan_%s_%04d_%02d.pdf", cabang, report.Tahun, report.Bulan)))
+	c.Data(http.StatusOK, "application/pdf", buf.Bytes())
+}
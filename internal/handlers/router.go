@@ -1,16 +1,22 @@
 package handlers
 
 import (
+	"log"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/web-dashboard-made-by-renz/backend/config"
 	"github.com/web-dashboard-made-by-renz/backend/internal/middleware"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/service"
 )
 
-func SetupRouter(cfg *config.Config, colorisHandler *ColorisHandler, trainingHandler *TrainingHandler, selloutHandler *SelloutHandler, authHandler *AuthHandler) *gin.Engine {
-	router := gin.Default()
+func SetupRouter(cfg *config.Config, authService service.AuthService, apiKeyService service.APIKeyService, colorisHandler *ColorisHandler, trainingHandler *TrainingHandler, selloutHandler *SelloutHandler, authHandler *AuthHandler, userHandler *UserHandler, apiKeyHandler *APIKeyHandler, signingKeyHandler *SigningKeyHandler, ssoHandler *SSOHandler, auditHandler *AuditHandler, importJobHandler *ImportJobHandler, reportHandler *ReportHandler) *gin.Engine {
+	router := newEngine(cfg)
+	router.Use(middleware.RequestID())
+	authMiddleware := middleware.AuthMiddleware(authService, apiKeyService)
+
 	registerRoutes := func(group *gin.RouterGroup) {
 		group.GET("/health", func(c *gin.Context) {
 			c.JSON(200, gin.H{
@@ -19,62 +25,129 @@ func SetupRouter(cfg *config.Config, colorisHandler *ColorisHandler, trainingHan
 			})
 		})
 
+		group.GET("/.well-known/jwks.json", signingKeyHandler.JWKS)
+
 		// Auth routes (public)
 		auth := group.Group("/auth")
 		{
 			auth.POST("/login", authHandler.Login)
-			auth.GET("/verify", middleware.AuthMiddleware(cfg.JWTSecret), authHandler.Verify)
+			auth.POST("/refresh", authHandler.Refresh)
+			auth.GET("/verify", authMiddleware, authHandler.Verify)
+			auth.POST("/logout", authMiddleware, authHandler.Logout)
+			auth.POST("/logout-all", authMiddleware, authHandler.LogoutAll)
+
+			// Single sign-on lewat identity provider eksternal (OIDC)
+			auth.GET("/sso", ssoHandler.GetProviders)
+			auth.GET("/sso/:provider/login", ssoHandler.Login)
+			auth.GET("/sso/:provider/callback", ssoHandler.Callback)
+			auth.POST("/sso/:provider/callback", ssoHandler.Callback)
+
+			apiKeys := auth.Group("/api-keys")
+			apiKeys.Use(authMiddleware)
+			{
+				apiKeys.POST("", apiKeyHandler.CreateAPIKey)
+				apiKeys.GET("", apiKeyHandler.GetAPIKeys)
+				apiKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+			}
+
+			auth.POST("/keys/rotate", authMiddleware, middleware.RequirePermission(models.PermissionUsersManage), signingKeyHandler.Rotate)
+
+			users := auth.Group("/users")
+			users.Use(authMiddleware, middleware.RequirePermission(models.PermissionUsersManage))
+			{
+				users.POST("", userHandler.CreateUser)
+				users.GET("", userHandler.GetAllUsers)
+				users.PUT("/:id/disable", userHandler.DisableUser)
+				users.PUT("/:id/enable", userHandler.EnableUser)
+				users.PUT("/:id/role", userHandler.UpdateRole)
+				users.PUT("/:id/scope", userHandler.UpdateScope)
+				users.PUT("/:id/password", userHandler.ResetPassword)
+				users.DELETE("/:id/sessions", userHandler.RevokeSessions)
+				users.POST("/:id/unlock", userHandler.Unlock)
+				users.GET("/:id/login-attempts", userHandler.GetLoginAttempts)
+			}
 		}
 
 		// Protected routes
 		protected := group.Group("")
-		protected.Use(middleware.AuthMiddleware(cfg.JWTSecret))
+		protected.Use(authMiddleware)
 		{
+			canRead := middleware.RequirePermission(models.PermissionDataRead)
+			canWrite := middleware.RequirePermission(models.PermissionDataWrite)
+			canDelete := middleware.RequirePermission(models.PermissionDataDelete)
+			canImport := middleware.RequirePermission(models.PermissionDataImport)
+			canRollback := middleware.RequirePermission(models.PermissionDataRollback)
+			canExport := middleware.RequirePermission(models.PermissionDataExport)
+			limitUpload := middleware.LimitBody(cfg.ImportMaxUploadSize)
+
 			coloris := protected.Group("/coloris")
 			{
-				coloris.POST("", colorisHandler.CreateColoris)
-				coloris.GET("", colorisHandler.GetAllColoris)
-				coloris.GET("/:id", colorisHandler.GetColorisById)
-				coloris.PUT("/:id", colorisHandler.UpdateColoris)
-				coloris.DELETE("/:id", colorisHandler.DeleteColoris)
-				coloris.POST("/import", colorisHandler.ImportExcel)
-				coloris.GET("/export", colorisHandler.ExportExcel)
+				coloris.POST("", canWrite, colorisHandler.CreateColoris)
+				coloris.GET("", canRead, colorisHandler.GetAllColoris)
+				coloris.GET("/:id", canRead, colorisHandler.GetColorisById)
+				coloris.PUT("/:id", canWrite, colorisHandler.UpdateColoris)
+				coloris.DELETE("/:id", canDelete, colorisHandler.DeleteColoris)
+				coloris.POST("/import", canImport, limitUpload, colorisHandler.ImportExcel)
+				coloris.POST("/import/sheets", canImport, limitUpload, colorisHandler.ImportSheets)
+				coloris.GET("/template", canImport, colorisHandler.DownloadTemplate)
+				coloris.GET("/export", canExport, colorisHandler.ExportExcel)
 			}
 
 			training := protected.Group("/training")
 			{
-				training.POST("", trainingHandler.CreateTraining)
-				training.GET("", trainingHandler.GetAllTraining)
-				training.GET("/:id", trainingHandler.GetTrainingById)
-				training.PUT("/:id", trainingHandler.UpdateTraining)
-				training.DELETE("/:id", trainingHandler.DeleteTraining)
-				training.POST("/import", trainingHandler.ImportExcel)
-				training.GET("/export", trainingHandler.ExportExcel)
+				training.POST("", canWrite, trainingHandler.CreateTraining)
+				training.GET("", canRead, trainingHandler.GetAllTraining)
+				training.GET("/:id", canRead, trainingHandler.GetTrainingById)
+				training.PUT("/:id", canWrite, trainingHandler.UpdateTraining)
+				training.DELETE("/:id", canDelete, trainingHandler.DeleteTraining)
+				training.POST("/import", canImport, limitUpload, trainingHandler.ImportExcel)
+				training.POST("/import/sheets", canImport, limitUpload, trainingHandler.ImportSheets)
+				training.GET("/template", canImport, trainingHandler.DownloadTemplate)
+				training.GET("/export", canExport, trainingHandler.ExportExcel)
+			}
+
+			protected.GET("/audit-logs", middleware.RequirePermission(models.PermissionAuditRead), auditHandler.GetAuditLogs)
+
+			importJobs := protected.Group("/import-jobs")
+			{
+				importJobs.GET("", canImport, importJobHandler.GetJobs)
+				importJobs.GET("/:id", canImport, importJobHandler.GetJob)
+				importJobs.POST("/:id/cancel", canImport, importJobHandler.CancelJob)
+				importJobs.POST("/:id/rollback", canRollback, importJobHandler.RollbackJob)
 			}
 
 			sellout := protected.Group("/sellout")
 			{
-				sellout.POST("", selloutHandler.CreateSellout)
-				sellout.GET("", selloutHandler.GetAllSellout)
-				sellout.GET("/:id", selloutHandler.GetSelloutById)
-				sellout.PUT("/:id", selloutHandler.UpdateSellout)
-				sellout.DELETE("/:id", selloutHandler.DeleteSellout)
-				sellout.POST("/import", selloutHandler.ImportExcel)
-				sellout.GET("/export", selloutHandler.ExportExcel)
+				sellout.POST("", canWrite, selloutHandler.CreateSellout)
+				sellout.GET("", canRead, selloutHandler.GetAllSellout)
+				sellout.GET("/:id", canRead, selloutHandler.GetSelloutById)
+				sellout.PUT("/:id", canWrite, selloutHandler.UpdateSellout)
+				sellout.DELETE("/:id", canDelete, selloutHandler.DeleteSellout)
+				sellout.POST("/import", canImport, limitUpload, selloutHandler.ImportExcel)
+				sellout.POST("/import/sheets", canImport, limitUpload, selloutHandler.ImportSheets)
+				sellout.GET("/template", canImport, selloutHandler.DownloadTemplate)
+				sellout.GET("/export", canExport, selloutHandler.ExportExcel)
 			}
+
+			protected.GET("/reports/monthly.pdf", canExport, reportHandler.MonthlyPDF)
 		}
 	}
 
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{cfg.AllowedOrigins},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key", middleware.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", "Content-Disposition", middleware.RequestIDHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
 
-	router.MaxMultipartMemory = 32 << 20
+	// File upload yang lebih besar dari ini disimpan net/http ke file sementara, bukan di
+	// memori, supaya import file besar tidak menghabiskan RAM.
+	router.MaxMultipartMemory = 1 << 20
+
+	// JWKS juga tersedia di root sesuai konvensi /.well-known
+	router.GET("/.well-known/jwks.json", signingKeyHandler.JWKS)
 
 	// Default API prefix
 	apiV1 := router.Group("/api/v1")
@@ -89,3 +162,13 @@ func SetupRouter(cfg *config.Config, colorisHandler *ColorisHandler, trainingHan
 
 	return router
 }
+
+// newEngine membuat engine gin yang hanya mempercayai X-Forwarded-For dari TRUSTED_PROXIES.
+// Tanpa itu IP client (dipakai throttle login, audit, dan session) bisa dipalsukan lewat header.
+func newEngine(cfg *config.Config) *gin.Engine {
+	router := gin.Default()
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
+	return router
+}
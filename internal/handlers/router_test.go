@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web-dashboard-made-by-renz/backend/config"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
+	"github.com/web-dashboard-made-by-renz/backend/internal/service"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type fakeUserRepository struct {
+	repository.UserRepository
+}
+
+func (fakeUserRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	return nil, mongo.ErrNoDocuments
+}
+
+type fakeLoginAttemptRepository struct {
+	throttles map[string]*models.LoginThrottle
+}
+
+func (r *fakeLoginAttemptRepository) Create(ctx context.Context, attempt *models.LoginAttempt) error {
+	return nil
+}
+
+func (r *fakeLoginAttemptRepository) FindByUsername(ctx context.Context, username string, page, perPage int) ([]models.LoginAttempt, int64, error) {
+	return nil, 0, nil
+}
+
+func (r *fakeLoginAttemptRepository) FindThrottles(ctx context.Context, keys []string) ([]models.LoginThrottle, error) {
+	var throttles []models.LoginThrottle
+	for _, key := range keys {
+		if t, ok := r.throttles[key]; ok {
+			throttles = append(throttles, *t)
+		}
+	}
+	return throttles, nil
+}
+
+func (r *fakeLoginAttemptRepository) IncrementFailure(ctx context.Context, key string, window time.Duration) (*models.LoginThrottle, error) {
+	t, ok := r.throttles[key]
+	if !ok {
+		t = &models.LoginThrottle{Key: key}
+		r.throttles[key] = t
+	}
+	t.Failures++
+	return t, nil
+}
+
+func (r *fakeLoginAttemptRepository) SetLockedUntil(ctx context.Context, key string, until time.Time) error {
+	r.throttles[key].LockedUntil = until
+	return nil
+}
+
+func (r *fakeLoginAttemptRepository) DeleteThrottle(ctx context.Context, key string) error {
+	delete(r.throttles, key)
+	return nil
+}
+
+func TestLoginThrottleIgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name           string
+		trustedProxies []string
+		wantStatus     int
+	}{
+		// Tanpa proxy terpercaya, X-Forwarded-For yang berganti tidak membuat IP baru.
+		{"untrusted", nil, http.StatusTooManyRequests},
+		// Dari proxy terpercaya, setiap X-Forwarded-For dihitung sebagai client berbeda.
+		{"trusted proxy", []string{"192.0.2.1"}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := &fakeLoginAttemptRepository{throttles: map[string]*models.LoginThrottle{}}
+			guard := service.NewLoginGuard(attempts, service.LoginGuardPolicy{
+				MaxFailures:      100,
+				MaxFailuresPerIP: 3,
+				LockoutBase:      time.Minute,
+				LockoutMax:       time.Hour,
+				FailureWindow:    time.Hour,
+			})
+			authService := service.NewAuthService(nil, service.TokenConfig{}, fakeUserRepository{}, nil, guard)
+
+			router := newEngine(&config.Config{TrustedProxies: tt.trustedProxies})
+			router.POST("/login", NewAuthHandler(authService).Login)
+
+			var status int
+			for i := range 4 {
+				body := fmt.Sprintf(`{"username":"user%d","password":"salah"}`, i)
+				req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(body))
+				req.Header.Set("Content-Type", "application/json")
+				req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i+1))
+				req.RemoteAddr = "192.0.2.1:40000"
+
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				status = w.Code
+			}
+
+			if status != tt.wantStatus {
+				t.Errorf("status after 4 failures = %d, want %d", status, tt.wantStatus)
+			}
+		})
+	}
+}
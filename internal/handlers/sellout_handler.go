@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
 
@@ -12,11 +11,13 @@ import (
 
 type SelloutHandler struct {
 	service service.SelloutService
+	jobs    service.ImportJobService
 }
 
-func NewSelloutHandler(service service.SelloutService) *SelloutHandler {
+func NewSelloutHandler(service service.SelloutService, jobs service.ImportJobService) *SelloutHandler {
 	return &SelloutHandler{
 		service: service,
+		jobs:    jobs,
 	}
 }
 
@@ -29,7 +30,7 @@ func (h *SelloutHandler) CreateSellout(c *gin.Context) {
 
 	err := h.service.CreateSellout(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondDataError(c, err)
 		return
 	}
 
@@ -52,32 +53,9 @@ func (h *SelloutHandler) GetAllSellout(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
 
-	tahun := c.Query("tahun")
-	bulan := c.Query("bulan")
-	cabang := c.Query("cabang")
-
-	filters := make(map[string]string)
-	if tahun != "" {
-		filters["tahun"] = tahun
-	}
-	if bulan != "" {
-		filters["bulan"] = bulan
-	}
-	if cabang != "" {
-		filters["cabang"] = cabang
-	}
-
-	var response *models.SelloutListResponse
-	var err error
-
-	if len(filters) > 0 {
-		response, err = h.service.GetSelloutWithFilters(c.Request.Context(), filters, page, perPage)
-	} else {
-		response, err = h.service.GetAllSellout(c.Request.Context(), page, perPage)
-	}
-
+	response, err := h.service.GetSelloutWithFilters(c.Request.Context(), dataQuery(c), page, perPage)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondDataError(c, err)
 		return
 	}
 
@@ -95,7 +73,7 @@ func (h *SelloutHandler) UpdateSellout(c *gin.Context) {
 
 	err := h.service.UpdateSellout(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondDataError(c, err)
 		return
 	}
 
@@ -107,7 +85,7 @@ func (h *SelloutHandler) DeleteSellout(c *gin.Context) {
 
 	err := h.service.DeleteSellout(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondDataError(c, err)
 		return
 	}
 
@@ -115,51 +93,17 @@ func (h *SelloutHandler) DeleteSellout(c *gin.Context) {
 }
 
 func (h *SelloutHandler) ImportExcel(c *gin.Context) {
-	file, err := c.FormFile("file")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak ditemukan"})
-		return
-	}
-
-	if file.Header.Get("Content-Type") != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" &&
-		file.Header.Get("Content-Type") != "application/vnd.ms-excel" {
-		ext := file.Filename[len(file.Filename)-5:]
-		if ext != ".xlsx" && ext != ".xls" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "File harus berformat Excel (.xlsx atau .xls)"})
-			return
-		}
-	}
+	importExcel(c, models.AuditEntitySellout, h.service, h.jobs)
+}
 
-	count, err := h.service.ImportFromExcel(c.Request.Context(), file)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
+func (h *SelloutHandler) ImportSheets(c *gin.Context) {
+	importSheets(c, h.service)
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Import berhasil",
-		"count":   count,
-	})
+func (h *SelloutHandler) DownloadTemplate(c *gin.Context) {
+	importTemplate(c, h.service, "sellout_template.xlsx")
 }
 
 func (h *SelloutHandler) ExportExcel(c *gin.Context) {
-	excelFile, err := h.service.ExportToExcel(c.Request.Context())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	defer excelFile.Close()
-
-	filename := fmt.Sprintf("sellout_data_%s.xlsx", c.Query("filename"))
-	if c.Query("filename") == "" {
-		filename = "sellout_data.xlsx"
-	}
-
-	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-
-	if err := excelFile.Write(c.Writer); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menulis file Excel"})
-		return
-	}
+	exportData(c, h.service, "sellout")
 }
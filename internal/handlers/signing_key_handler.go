@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web-dashboard-made-by-renz/backend/internal/service"
+)
+
+type SigningKeyHandler struct {
+	service service.SigningKeyService
+}
+
+func NewSigningKeyHandler(service service.SigningKeyService) *SigningKeyHandler {
+	return &SigningKeyHandler{
+		service: service,
+	}
+}
+
+// JWKS mempublikasikan public key supaya service lain bisa memverifikasi token kita.
+func (h *SigningKeyHandler) JWKS(c *gin.Context) {
+	jwks, err := h.service.JWKS(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, jwks)
+}
+
+func (h *SigningKeyHandler) Rotate(c *gin.Context) {
+	key, err := h.service.Rotate(c.Request.Context())
+	if errors.Is(err, service.ErrSigningKeyRotated) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Signing key baru saja dirotasi oleh proses lain, coba lagi"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Signing key berhasil dirotasi",
+		"data":    key,
+	})
+}
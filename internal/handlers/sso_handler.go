@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/service"
+)
+
+type SSOHandler struct {
+	service service.SSOService
+}
+
+func NewSSOHandler(service service.SSOService) *SSOHandler {
+	return &SSOHandler{
+		service: service,
+	}
+}
+
+func (h *SSOHandler) GetProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.service.Providers()})
+}
+
+// Login mengarahkan browser ke halaman login provider. Frontend yang ingin
+// mengatur redirect sendiri bisa memakai ?redirect=false untuk mendapat auth_url.
+func (h *SSOHandler) Login(c *gin.Context) {
+	response, err := h.service.Start(c.Request.Context(), c.Param("provider"))
+	if err != nil {
+		respondSSOError(c, err)
+		return
+	}
+
+	if c.Query("redirect") == "false" {
+		c.JSON(http.StatusOK, gin.H{"data": response})
+		return
+	}
+
+	c.Redirect(http.StatusFound, response.AuthURL)
+}
+
+// Callback menerima code dan state dari provider, lewat query (GET, redirect langsung
+// dari provider) atau body JSON (POST, diteruskan oleh frontend).
+func (h *SSOHandler) Callback(c *gin.Context) {
+	if providerError := c.Query("error"); providerError != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":             "SSO login was not completed",
+			"provider_error":    providerError,
+			"error_description": c.Query("error_description"),
+		})
+		return
+	}
+
+	var req models.SSOCallbackRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Missing code or state"})
+		return
+	}
+
+	client := models.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+
+	response, err := h.service.Callback(c.Request.Context(), c.Param("provider"), req.Code, req.State, client)
+	if err != nil {
+		respondSSOError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Login successful",
+		"data":    response,
+	})
+}
+
+func respondSSOError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrUnknownProvider):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown identity provider"})
+	case errors.Is(err, service.ErrInvalidSSOState):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid or expired SSO state, please start the login again"})
+	case errors.Is(err, service.ErrSSOLoginFailed):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "SSO login failed"})
+	case errors.Is(err, service.ErrSSOUserNotFound):
+		c.JSON(http.StatusForbidden, gin.H{"error": "No account is registered for this SSO user"})
+	case errors.Is(err, service.ErrSSOUserConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "Username is already used by another account"})
+	case errors.Is(err, service.ErrAccountDisabled):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Account is disabled"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 	"strconv"
 
@@ -12,11 +11,13 @@ import (
 
 type TrainingHandler struct {
 	service service.TrainingService
+	jobs    service.ImportJobService
 }
 
-func NewTrainingHandler(service service.TrainingService) *TrainingHandler {
+func NewTrainingHandler(service service.TrainingService, jobs service.ImportJobService) *TrainingHandler {
 	return &TrainingHandler{
 		service: service,
+		jobs:    jobs,
 	}
 }
 
@@ -29,7 +30,7 @@ func (h *TrainingHandler) CreateTraining(c *gin.Context) {
 
 	err := h.service.CreateTraining(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondDataError(c, err)
 		return
 	}
 
@@ -52,32 +53,9 @@ func (h *TrainingHandler) GetAllTraining(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
 
-	region := c.Query("region")
-	cabangArea := c.Query("cabang_area")
-	bulan := c.Query("bulan")
-
-	filters := make(map[string]string)
-	if region != "" {
-		filters["region"] = region
-	}
-	if cabangArea != "" {
-		filters["cabang_area"] = cabangArea
-	}
-	if bulan != "" {
-		filters["bulan"] = bulan
-	}
-
-	var response *models.TrainingListResponse
-	var err error
-
-	if len(filters) > 0 {
-		response, err = h.service.GetTrainingWithFilters(c.Request.Context(), filters, page, perPage)
-	} else {
-		response, err = h.service.GetAllTraining(c.Request.Context(), page, perPage)
-	}
-
+	response, err := h.service.GetTrainingWithFilters(c.Request.Context(), dataQuery(c), page, perPage)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondDataError(c, err)
 		return
 	}
 
@@ -95,7 +73,7 @@ func (h *TrainingHandler) UpdateTraining(c *gin.Context) {
 
 	err := h.service.UpdateTraining(c.Request.Context(), id, &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondDataError(c, err)
 		return
 	}
 
@@ -107,7 +85,7 @@ func (h *TrainingHandler) DeleteTraining(c *gin.Context) {
 
 	err := h.service.DeleteTraining(c.Request.Context(), id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondDataError(c, err)
 		return
 	}
 
@@ -115,51 +93,17 @@ func (h *TrainingHandler) DeleteTraining(c *gin.Context) {
 }
 
 func (h *TrainingHandler) ImportExcel(c *gin.Context) {
-	file, err := c.FormFile("file")
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "File tidak ditemukan"})
-		return
-	}
-
-	if file.Header.Get("Content-Type") != "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet" &&
-		file.Header.Get("Content-Type") != "application/vnd.ms-excel" {
-		ext := file.Filename[len(file.Filename)-5:]
-		if ext != ".xlsx" && ext != ".xls" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "File harus berformat Excel (.xlsx atau .xls)"})
-			return
-		}
-	}
+	importExcel(c, models.AuditEntityTraining, h.service, h.jobs)
+}
 
-	count, err := h.service.ImportFromExcel(c.Request.Context(), file)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
+func (h *TrainingHandler) ImportSheets(c *gin.Context) {
+	importSheets(c, h.service)
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Import berhasil",
-		"count":   count,
-	})
+func (h *TrainingHandler) DownloadTemplate(c *gin.Context) {
+	importTemplate(c, h.service, "training_template.xlsx")
 }
 
 func (h *TrainingHandler) ExportExcel(c *gin.Context) {
-	excelFile, err := h.service.ExportToExcel(c.Request.Context())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-		return
-	}
-	defer excelFile.Close()
-
-	filename := fmt.Sprintf("training_data_%s.xlsx", c.Query("filename"))
-	if c.Query("filename") == "" {
-		filename = "training_data.xlsx"
-	}
-
-	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
-	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
-
-	if err := excelFile.Write(c.Writer); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Gagal menulis file Excel"})
-		return
-	}
+	exportData(c, h.service, "training")
 }
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/service"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type UserHandler struct {
+	service service.UserService
+}
+
+func NewUserHandler(service service.UserService) *UserHandler {
+	return &UserHandler{
+		service: service,
+	}
+}
+
+func (h *UserHandler) CreateUser(c *gin.Context) {
+	var req models.UserCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.service.CreateUser(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrUserExists) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "User berhasil dibuat",
+		"data":    user,
+	})
+}
+
+func (h *UserHandler) GetAllUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	response, err := h.service.GetAllUsers(c.Request.Context(), page, perPage)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *UserHandler) DisableUser(c *gin.Context) {
+	if err := h.service.DisableUser(c.Request.Context(), c.Param("id")); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User berhasil dinonaktifkan"})
+}
+
+func (h *UserHandler) EnableUser(c *gin.Context) {
+	if err := h.service.EnableUser(c.Request.Context(), c.Param("id")); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User berhasil diaktifkan"})
+}
+
+func (h *UserHandler) UpdateRole(c *gin.Context) {
+	var req models.UserUpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.UpdateRole(c.Request.Context(), c.Param("id"), req.Role); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role user berhasil diubah"})
+}
+
+func (h *UserHandler) UpdateScope(c *gin.Context) {
+	var req models.UserUpdateScopeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.UpdateScope(c.Request.Context(), c.Param("id"), req.Scope); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Scope user berhasil diubah"})
+}
+
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var req models.UserResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.service.ResetPassword(c.Request.Context(), c.Param("id"), req.Password); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password berhasil direset"})
+}
+
+func (h *UserHandler) RevokeSessions(c *gin.Context) {
+	count, err := h.service.RevokeSessions(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Semua session user berhasil dicabut",
+		"count":   count,
+	})
+}
+
+func (h *UserHandler) Unlock(c *gin.Context) {
+	if err := h.service.Unlock(c.Request.Context(), c.Param("id")); err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User berhasil di-unlock"})
+}
+
+func (h *UserHandler) GetLoginAttempts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	response, err := h.service.GetLoginAttempts(c.Request.Context(), c.Param("id"), page, perPage)
+	if err != nil {
+		respondUserError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func respondUserError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments), errors.Is(err, primitive.ErrInvalidHex):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User tidak ditemukan"})
+	case errors.Is(err, bcrypt.ErrPasswordTooLong):
+		// Batas bcrypt dihitung dalam byte, jadi password multibyte bisa lolos max=72.
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Password maksimal 72 byte"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
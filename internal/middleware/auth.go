@@ -1,15 +1,25 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
+	"github.com/web-dashboard-made-by-renz/backend/internal/auth"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/service"
 )
 
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+func AuthMiddleware(authService service.AuthService, apiKeyService service.APIKeyService) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Script (ETL, cron) boleh memakai API key sebagai pengganti Bearer JWT
+		if apiKey := c.GetHeader("X-API-Key"); apiKey != "" {
+			authenticateAPIKey(c, apiKeyService, apiKey)
+			return
+		}
+
 		// Get token from Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -26,27 +36,85 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		tokenString := parts[1]
-
-		// Parse and validate token
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Validate signing method
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, jwt.ErrSignatureInvalid
+		claims, err := authService.ValidateToken(c.Request.Context(), parts[1])
+		if err != nil {
+			switch {
+			case errors.Is(err, service.ErrSessionRevoked):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session has been revoked"})
+			case errors.Is(err, service.ErrInvalidToken):
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			}
-			return []byte(jwtSecret), nil
-		})
-
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
 			c.Abort()
 			return
 		}
 
-		// Extract claims
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			c.Set("username", claims["username"])
-			c.Set("role", claims["role"])
+		setPrincipal(c, claims.Principal())
+		c.Next()
+	}
+}
+
+func authenticateAPIKey(c *gin.Context, apiKeyService service.APIKeyService, key string) {
+	client := models.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IP:        c.ClientIP(),
+	}
+
+	principal, err := apiKeyService.Authenticate(c.Request.Context(), key, client)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAPIKey):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or revoked API key"})
+		case errors.Is(err, service.ErrAccountDisabled):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Account is disabled"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		c.Abort()
+		return
+	}
+
+	setPrincipal(c, principal.Principal())
+	c.Next()
+}
+
+// setPrincipal menyimpan identitas request ke gin context. Scope region/cabang juga
+// diteruskan lewat context request supaya repository bisa membatasi query sesuai area user.
+func setPrincipal(c *gin.Context, principal *models.Principal) {
+	c.Set("principal", principal)
+	c.Set("user_id", principal.UserID)
+	c.Set("username", principal.Username)
+	c.Set("role", principal.Role)
+	c.Set("auth_method", principal.AuthMethod)
+	c.Set("permissions", principal.Permissions)
+	c.Set("session_id", principal.SessionID)
+	c.Set("jti", principal.TokenID)
+	c.Set("api_key_id", principal.APIKeyID)
+	if principal.ExpiresAt != nil && principal.AuthMethod == models.AuthMethodJWT {
+		c.Set("token_expires_at", *principal.ExpiresAt)
+	}
+
+	c.Set("scope", principal.Scope)
+	ctx := auth.WithScope(c.Request.Context(), principal.Scope)
+	c.Request = c.Request.WithContext(auth.WithPrincipal(ctx, principal))
+}
+
+// RequirePermission hanya meneruskan request kalau permission user (diset oleh
+// AuthMiddleware dari role atau API key) memuat permission yang diminta.
+// Selain itu dijawab 403 dengan format yang sama.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("permissions")
+		permissions, _ := value.([]string)
+
+		if !slices.Contains(permissions, permission) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":               "Insufficient permissions",
+				"required_permission": permission,
+			})
+			c.Abort()
+			return
 		}
 
 		c.Next()
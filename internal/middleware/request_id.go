@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/web-dashboard-made-by-renz/backend/internal/auth"
+	"github.com/web-dashboard-made-by-renz/backend/pkg/utils"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern membatasi X-Request-ID dari client supaya tidak bisa dipakai
+// untuk menyisipkan teks sembarang ke log.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID memberi setiap request sebuah ID (dari header X-Request-ID kalau valid,
+// atau dibuat baru), mengembalikannya di response, dan menyimpannya di context
+// bersama IP dan user agent client.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !requestIDPattern.MatchString(requestID) {
+			requestID, _ = utils.RandomToken(12)
+		}
+
+		c.Set("request_id", requestID)
+		c.Header(RequestIDHeader, requestID)
+		c.Request = c.Request.WithContext(auth.WithRequestInfo(c.Request.Context(), auth.RequestInfo{
+			RequestID: requestID,
+			IP:        c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}))
+
+		c.Next()
+	}
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey adalah key pribadi untuk script (mis. ETL) yang dikirim lewat header X-API-Key.
+// Hanya hash dari key yang disimpan; key asli hanya ditampilkan sekali saat dibuat.
+type APIKey struct {
+	ID                primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID            primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Username          string             `json:"username" bson:"username"`
+	Name              string             `json:"name" bson:"name"`
+	Prefix            string             `json:"prefix" bson:"prefix"`
+	KeyHash           string             `json:"-" bson:"key_hash"`
+	Permissions       []string           `json:"permissions" bson:"permissions"`
+	ExpiresAt         *time.Time         `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	RevokedAt         *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+	LastUsedAt        *time.Time         `json:"last_used_at,omitempty" bson:"last_used_at,omitempty"`
+	LastUsedIP        string             `json:"last_used_ip,omitempty" bson:"last_used_ip,omitempty"`
+	LastUsedUserAgent string             `json:"last_used_user_agent,omitempty" bson:"last_used_user_agent,omitempty"`
+	CreatedAt         time.Time          `json:"created_at" bson:"created_at"`
+}
+
+type APIKeyCreateRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Permissions   []string `json:"permissions"`
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=3650"`
+}
+
+type APIKeyCreateResponse struct {
+	Key    string `json:"key"`
+	APIKey APIKey `json:"api_key"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	AuditEntityColoris  = "coloris"
+	AuditEntityTraining = "training"
+	AuditEntitySellout  = "sellout"
+
+	AuditActionCreate   = "create"
+	AuditActionUpdate   = "update"
+	AuditActionDelete   = "delete"
+	AuditActionImport   = "import"
+	AuditActionRollback = "rollback"
+)
+
+// AuditActor adalah user (atau API key milik user) yang melakukan perubahan.
+type AuditActor struct {
+	UserID     string `json:"user_id" bson:"user_id"`
+	Username   string `json:"username" bson:"username"`
+	Role       string `json:"role" bson:"role"`
+	AuthMethod string `json:"auth_method" bson:"auth_method"`
+	APIKeyID   string `json:"api_key_id,omitempty" bson:"api_key_id,omitempty"`
+}
+
+// AuditChange adalah nilai satu field sebelum dan sesudah perubahan.
+// Before kosong untuk create/import, After kosong untuk delete.
+type AuditChange struct {
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+// AuditLog mencatat satu perubahan data beserta pelakunya dan field yang berubah.
+type AuditLog struct {
+	ID        primitive.ObjectID     `json:"id" bson:"_id,omitempty"`
+	Entity    string                 `json:"entity" bson:"entity"`
+	EntityID  string                 `json:"entity_id" bson:"entity_id"`
+	Action    string                 `json:"action" bson:"action"`
+	Actor     AuditActor             `json:"actor" bson:"actor"`
+	Changes   map[string]AuditChange `json:"changes" bson:"changes"`
+	IP        string                 `json:"ip" bson:"ip"`
+	UserAgent string                 `json:"user_agent" bson:"user_agent"`
+	RequestID string                 `json:"request_id" bson:"request_id"`
+	CreatedAt time.Time              `json:"created_at" bson:"created_at"`
+}
+
+type AuditLogFilter struct {
+	Entity   string
+	EntityID string
+	Action   string
+	User     string
+	From     *time.Time
+	To       *time.Time
+}
+
+type AuditLogListResponse struct {
+	Data       []AuditLog `json:"data"`
+	Total      int64      `json:"total"`
+	Page       int        `json:"page"`
+	PerPage    int        `json:"per_page"`
+	TotalPages int        `json:"total_pages"`
+}
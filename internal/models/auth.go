@@ -1,16 +1,36 @@
 package models
 
+import "time"
+
+const (
+	AuthMethodJWT    = "jwt"
+	AuthMethodAPIKey = "api_key"
+)
+
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
 }
 
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	User         User   `json:"user"`
 }
 
-type User struct {
-	Username string `json:"username"`
-	Role     string `json:"role"`
+// Principal adalah identitas yang sudah terautentikasi untuk satu request,
+// baik lewat access token maupun API key.
+type Principal struct {
+	UserID      string     `json:"id"`
+	Username    string     `json:"username"`
+	DisplayName string     `json:"display_name,omitempty"`
+	Role        string     `json:"role"`
+	Scope       DataScope  `json:"scope"`
+	Permissions []string   `json:"permissions"`
+	AuthMethod  string     `json:"auth_method"`
+	SessionID   string     `json:"session_id,omitempty"`
+	TokenID     string     `json:"-"`
+	APIKeyID    string     `json:"api_key_id,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
 }
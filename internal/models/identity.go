@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// ExternalIdentity adalah data user yang dikembalikan identity provider (SSO)
+// setelah login berhasil, sebelum dipetakan ke user lokal.
+type ExternalIdentity struct {
+	Provider    string
+	Subject     string
+	Username    string
+	DisplayName string
+	Email       string
+	Groups      []string
+	Region      string
+	Cabang      string
+}
+
+// SSOState menyimpan state, nonce dan PKCE verifier satu alur login SSO.
+// Disimpan di MongoDB (bukan memory) supaya callback bisa diterima instance mana pun.
+type SSOState struct {
+	State        string    `json:"state" bson:"_id"`
+	Provider     string    `json:"provider" bson:"provider"`
+	Nonce        string    `json:"-" bson:"nonce"`
+	CodeVerifier string    `json:"-" bson:"code_verifier"`
+	CreatedAt    time.Time `json:"created_at" bson:"created_at"`
+	ExpiresAt    time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+type SSOStartResponse struct {
+	AuthURL string `json:"auth_url"`
+	State   string `json:"state"`
+}
+
+type SSOCallbackRequest struct {
+	Code  string `json:"code" form:"code" binding:"required"`
+	State string `json:"state" form:"state" binding:"required"`
+}
@@ -0,0 +1,174 @@
+package models
+
+import "time"
+
+const (
+	// ImportModeInsert hanya menambah data baru; baris yang kuncinya sudah ada ditolak.
+	ImportModeInsert = "insert"
+	// ImportModeUpsert memperbarui data yang kuncinya sudah ada dan menambah sisanya.
+	ImportModeUpsert = "upsert"
+	// ImportModeReplace menghapus semua data di periode yang ada di file, lalu menyimpan isi file.
+	ImportModeReplace = "replace"
+
+	ImportActionInsert = "insert"
+	ImportActionUpdate = "update"
+
+	ImportRowAccepted = "accepted"
+	ImportRowSkipped  = "skipped"
+	ImportRowError    = "error"
+
+	importSampleSize    = 5
+	importMaxReportRows = 1000
+)
+
+// ImportFieldError menjelaskan satu kolom yang tidak valid di sebuah baris.
+type ImportFieldError struct {
+	Column string `json:"column" bson:"column"`
+	Value  string `json:"value,omitempty" bson:"value,omitempty"`
+	Reason string `json:"reason" bson:"reason"`
+}
+
+// ImportRowResult adalah hasil validasi satu baris. Row memakai nomor baris Excel
+// (header = baris 1) supaya mudah dicari di spreadsheet. Sheet hanya diisi saat
+// semua sheet diimport.
+type ImportRowResult struct {
+	Sheet  string             `json:"sheet,omitempty" bson:"sheet,omitempty"`
+	Row    int                `json:"row" bson:"row"`
+	Status string             `json:"status" bson:"status"`
+	Action string             `json:"action,omitempty" bson:"action,omitempty"`
+	Reason string             `json:"reason,omitempty" bson:"reason,omitempty"`
+	Errors []ImportFieldError `json:"errors,omitempty" bson:"errors,omitempty"`
+}
+
+// ImportReport merangkum hasil import (atau dry run) per baris. Sample tidak ikut
+// disimpan di import job.
+type ImportReport struct {
+	DryRun         bool                `json:"dry_run" bson:"dry_run"`
+	Mode           string              `json:"mode" bson:"mode"`
+	Format         string              `json:"format,omitempty" bson:"format,omitempty"`
+	TotalRows      int                 `json:"total_rows" bson:"total_rows"`
+	Accepted       int                 `json:"accepted" bson:"accepted"`
+	Skipped        int                 `json:"skipped" bson:"skipped"`
+	Errored        int                 `json:"errored" bson:"errored"`
+	Inserted       int                 `json:"inserted" bson:"inserted"`
+	Updated        int                 `json:"updated" bson:"updated"`
+	Deleted        int                 `json:"deleted" bson:"deleted"`
+	IgnoredColumns []string            `json:"ignored_columns,omitempty" bson:"ignored_columns,omitempty"`
+	Sheets         []ImportSheetReport `json:"sheets,omitempty" bson:"sheets,omitempty"`
+	Rows           []ImportRowResult   `json:"rows" bson:"rows"`
+	RowsTruncated  bool                `json:"rows_truncated,omitempty" bson:"rows_truncated,omitempty"`
+	Sample         []interface{}       `json:"sample" bson:"-"`
+
+	// kept menghitung baris yang disimpan di Rows per status.
+	kept map[string]int
+}
+
+// ImportSheetReport adalah hasil import satu sheet workbook. Sheet yang tidak bisa
+// diimport (mis. kolom wajib tidak ada) dicatat di Error dan dilewati.
+type ImportSheetReport struct {
+	Sheet          string   `json:"sheet" bson:"sheet"`
+	TotalRows      int      `json:"total_rows" bson:"total_rows"`
+	Accepted       int      `json:"accepted" bson:"accepted"`
+	Skipped        int      `json:"skipped" bson:"skipped"`
+	Errored        int      `json:"errored" bson:"errored"`
+	Inserted       int      `json:"inserted" bson:"inserted"`
+	Updated        int      `json:"updated" bson:"updated"`
+	IgnoredColumns []string `json:"ignored_columns,omitempty" bson:"ignored_columns,omitempty"`
+	MissingColumns []string `json:"missing_columns,omitempty" bson:"missing_columns,omitempty"`
+	Error          string   `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// AddRow menghitung hasil satu baris di sheet ini.
+func (s *ImportSheetReport) AddRow(result ImportRowResult) {
+	s.TotalRows++
+	switch result.Status {
+	case ImportRowAccepted:
+		s.Accepted++
+		switch result.Action {
+		case ImportActionInsert:
+			s.Inserted++
+		case ImportActionUpdate:
+			s.Updated++
+		}
+	case ImportRowSkipped:
+		s.Skipped++
+	case ImportRowError:
+		s.Errored++
+	}
+}
+
+// ImportSheet meringkas satu sheet di file import supaya user bisa memilih sheet
+// sebelum import. Rows tidak termasuk header.
+type ImportSheet struct {
+	Index          int      `json:"index"`
+	Name           string   `json:"name"`
+	Rows           int      `json:"rows"`
+	Importable     bool     `json:"importable"`
+	IgnoredColumns []string `json:"ignored_columns,omitempty"`
+	MissingColumns []string `json:"missing_columns,omitempty"`
+	Error          string   `json:"error,omitempty"`
+}
+
+type ImportWorkbook struct {
+	Format string        `json:"format"`
+	Sheets []ImportSheet `json:"sheets"`
+}
+
+func IsValidImportMode(mode string) bool {
+	switch mode {
+	case ImportModeInsert, ImportModeUpsert, ImportModeReplace:
+		return true
+	}
+	return false
+}
+
+func NewImportReport(dryRun bool, mode string) *ImportReport {
+	return &ImportReport{
+		DryRun: dryRun,
+		Mode:   mode,
+		Rows:   []ImportRowResult{},
+		Sample: []interface{}{},
+	}
+}
+
+// AddRow mencatat hasil satu baris. Detail baris dibatasi per status supaya response
+// untuk file besar tetap kecil, tanpa baris error/skipped tergeser oleh baris yang
+// diterima; hitungannya tetap lengkap.
+func (r *ImportReport) AddRow(result ImportRowResult) {
+	r.TotalRows++
+	switch result.Status {
+	case ImportRowAccepted:
+		r.Accepted++
+	case ImportRowSkipped:
+		r.Skipped++
+	case ImportRowError:
+		r.Errored++
+	}
+
+	if r.kept == nil {
+		r.kept = map[string]int{}
+	}
+	if r.kept[result.Status] >= importMaxReportRows {
+		r.RowsTruncated = true
+		return
+	}
+	r.kept[result.Status]++
+	r.Rows = append(r.Rows, result)
+}
+
+// AddSample menyimpan beberapa record pertama hasil parsing sebagai contoh.
+func (r *ImportReport) AddSample(record interface{}) {
+	if len(r.Sample) < importSampleSize {
+		r.Sample = append(r.Sample, record)
+	}
+}
+
+// ImportSource mencatat asal data yang masuk lewat import. import_batch_id sama dengan
+// ID job import, dipakai untuk melihat riwayat dan me-rollback satu batch.
+type ImportSource struct {
+	ImportBatchID  string     `json:"import_batch_id,omitempty" bson:"import_batch_id,omitempty"`
+	ImportFileName string     `json:"import_file_name,omitempty" bson:"import_file_name,omitempty"`
+	ImportChecksum string     `json:"import_checksum,omitempty" bson:"import_checksum,omitempty"`
+	ImportedBy     string     `json:"imported_by,omitempty" bson:"imported_by,omitempty"`
+	ImportedAt     *time.Time `json:"imported_at,omitempty" bson:"imported_at,omitempty"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ImportJobQueued    = "queued"
+	ImportJobRunning   = "running"
+	ImportJobCompleted = "completed"
+	ImportJobFailed    = "failed"
+	ImportJobCancelled = "cancelled"
+)
+
+// ImportProgress adalah kemajuan import. Total adalah jumlah baris data yang tidak kosong;
+// Processed termasuk baris yang gagal.
+type ImportProgress struct {
+	Total     int `json:"total" bson:"total"`
+	Processed int `json:"processed" bson:"processed"`
+	Failed    int `json:"failed" bson:"failed"`
+	Inserted  int `json:"inserted" bson:"inserted"`
+	Updated   int `json:"updated" bson:"updated"`
+	Deleted   int `json:"deleted" bson:"deleted"`
+}
+
+// ImportJob adalah import Excel yang diproses di background.
+type ImportJob struct {
+	ID              primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Entity          string             `json:"entity" bson:"entity"`
+	Status          string             `json:"status" bson:"status"`
+	Mode            string             `json:"mode" bson:"mode"`
+	FileName        string             `json:"file_name" bson:"file_name"`
+	Sheet           string             `json:"sheet,omitempty" bson:"sheet,omitempty"`
+	Checksum        string             `json:"checksum" bson:"checksum"`
+	Progress        ImportProgress     `json:"progress" bson:"progress"`
+	Error           string             `json:"error,omitempty" bson:"error,omitempty"`
+	Report          *ImportReport      `json:"report,omitempty" bson:"report,omitempty"`
+	CreatedBy       AuditActor         `json:"created_by" bson:"created_by"`
+	CancelRequested bool               `json:"cancel_requested" bson:"cancel_requested"`
+	Rollback        *ImportRollback    `json:"rollback,omitempty" bson:"rollback,omitempty"`
+	CreatedAt       time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time          `json:"updated_at" bson:"updated_at"`
+	StartedAt       *time.Time         `json:"started_at,omitempty" bson:"started_at,omitempty"`
+	FinishedAt      *time.Time         `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+}
+
+func (j *ImportJob) IsFinished() bool {
+	return j.Status == ImportJobCompleted || j.Status == ImportJobFailed || j.Status == ImportJobCancelled
+}
+
+// ImportBackup adalah versi data sebelum ditimpa atau dihapus oleh satu batch import.
+type ImportBackup struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Entity    string             `json:"entity" bson:"entity"`
+	BatchID   string             `json:"batch_id" bson:"batch_id"`
+	Document  interface{}        `json:"document" bson:"document"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// ImportRollback adalah hasil rollback satu batch import. Deleted adalah data batch yang
+// dihapus, Restored adalah versi sebelum import yang dikembalikan, Skipped adalah versi lama
+// yang tidak dikembalikan karena sudah ada lagi atau di luar scope user.
+type ImportRollback struct {
+	Restore    bool       `json:"restore" bson:"restore"`
+	Deleted    int        `json:"deleted" bson:"deleted"`
+	Restored   int        `json:"restored" bson:"restored"`
+	Skipped    int        `json:"skipped" bson:"skipped"`
+	Error      string     `json:"error,omitempty" bson:"error,omitempty"`
+	By         AuditActor `json:"by" bson:"by"`
+	StartedAt  time.Time  `json:"started_at" bson:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty" bson:"finished_at,omitempty"`
+}
+
+type ImportJobFilter struct {
+	Entity string
+	Status string
+	// UserID membatasi job ke milik satu user; kosong berarti semua job.
+	UserID string
+}
+
+type ImportJobListResponse struct {
+	Data       []ImportJob `json:"data"`
+	Total      int64       `json:"total"`
+	Page       int         `json:"page"`
+	PerPage    int         `json:"per_page"`
+	TotalPages int         `json:"total_pages"`
+}
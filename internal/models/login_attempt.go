@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	LoginOutcomeSuccess            = "success"
+	LoginOutcomeInvalidCredentials = "invalid_credentials"
+	LoginOutcomeAccountDisabled    = "account_disabled"
+	LoginOutcomeLocked             = "locked"
+	LoginOutcomeSSOSuccess         = "sso_success"
+)
+
+// LoginAttempt adalah catatan setiap percobaan login beserta hasilnya.
+type LoginAttempt struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Username  string             `json:"username" bson:"username"`
+	IP        string             `json:"ip" bson:"ip"`
+	UserAgent string             `json:"user_agent" bson:"user_agent"`
+	Outcome   string             `json:"outcome" bson:"outcome"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
+}
+
+// LoginThrottle menyimpan jumlah login gagal per key ("user:<username>" atau "ip:<ip>").
+// Disimpan di MongoDB supaya counter-nya sama di semua instance Cloud Function.
+type LoginThrottle struct {
+	Key           string    `json:"key" bson:"_id"`
+	Failures      int       `json:"failures" bson:"failures"`
+	LockedUntil   time.Time `json:"locked_until" bson:"locked_until"`
+	LastFailureAt time.Time `json:"last_failure_at" bson:"last_failure_at"`
+	ExpiresAt     time.Time `json:"expires_at" bson:"expires_at"`
+}
+
+type LoginAttemptListResponse struct {
+	Data       []LoginAttempt `json:"data"`
+	Total      int64          `json:"total"`
+	Page       int            `json:"page"`
+	PerPage    int            `json:"per_page"`
+	TotalPages int            `json:"total_pages"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// TestSummary adalah ringkasan nilai tes Coloris atau Training untuk satu materi.
+type TestSummary struct {
+	Materi       string  `json:"materi" bson:"materi"`
+	Participants int     `json:"participants" bson:"participants"`
+	Average      float64 `json:"average" bson:"average"`
+	Highest      float64 `json:"highest" bson:"highest"`
+	Lowest       float64 `json:"lowest" bson:"lowest"`
+}
+
+// MonthlyReport adalah isi laporan bulanan satu cabang.
+type MonthlyReport struct {
+	Tahun       int
+	Bulan       int
+	Cabang      string
+	Region      string
+	GeneratedAt time.Time
+
+	// Sellout adalah total bulan laporan; Trend berisi beberapa bulan terakhir
+	// sampai bulan laporan, urut dari yang terlama.
+	Sellout      SelloutSummary
+	Trend        []SelloutSummary
+	TopColorists []SelloutSummary
+
+	Coloris  []TestSummary
+	Training []TestSummary
+}
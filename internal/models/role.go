@@ -0,0 +1,64 @@
+package models
+
+const (
+	RoleViewer   = "viewer"
+	RoleEditor   = "editor"
+	RoleImporter = "importer"
+	RoleAdmin    = "admin"
+)
+
+const (
+	PermissionDataRead     = "data:read"
+	PermissionDataExport   = "data:export"
+	PermissionDataWrite    = "data:write"
+	PermissionDataDelete   = "data:delete"
+	PermissionDataImport   = "data:import"
+	PermissionDataRollback = "data:rollback"
+	PermissionUsersManage  = "users:manage"
+	PermissionAuditRead    = "audit:read"
+)
+
+// RolePermissions memetakan setiap role ke permission yang dimilikinya.
+// Role yang tidak terdaftar di sini tidak punya permission apa pun.
+var RolePermissions = map[string][]string{
+	RoleViewer: {
+		PermissionDataRead,
+		PermissionDataExport,
+	},
+	RoleEditor: {
+		PermissionDataRead,
+		PermissionDataExport,
+		PermissionDataWrite,
+		PermissionDataDelete,
+	},
+	RoleImporter: {
+		PermissionDataRead,
+		PermissionDataExport,
+		PermissionDataImport,
+		PermissionDataRollback,
+	},
+	RoleAdmin: {
+		PermissionDataRead,
+		PermissionDataExport,
+		PermissionDataWrite,
+		PermissionDataDelete,
+		PermissionDataImport,
+		PermissionDataRollback,
+		PermissionUsersManage,
+		PermissionAuditRead,
+	},
+}
+
+func HasPermission(role, permission string) bool {
+	for _, p := range RolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func IsValidRole(role string) bool {
+	_, ok := RolePermissions[role]
+	return ok
+}
@@ -0,0 +1,26 @@
+package models
+
+import "strings"
+
+// DataScope membatasi data yang boleh dilihat dan diubah seorang user.
+// Field yang kosong berarti tidak dibatasi pada level tersebut.
+type DataScope struct {
+	Region string `json:"region,omitempty" bson:"region,omitempty"`
+	Cabang string `json:"cabang,omitempty" bson:"cabang,omitempty"`
+}
+
+func (s DataScope) IsEmpty() bool {
+	return s.Region == "" && s.Cabang == ""
+}
+
+// Allows mengecek apakah sebuah record dengan region dan cabang tertentu
+// masih berada di dalam scope.
+func (s DataScope) Allows(region, cabang string) bool {
+	if s.Region != "" && !strings.EqualFold(strings.TrimSpace(region), s.Region) {
+		return false
+	}
+	if s.Cabang != "" && !strings.EqualFold(strings.TrimSpace(cabang), s.Cabang) {
+		return false
+	}
+	return true
+}
@@ -28,6 +28,9 @@ type Sellout struct {
 	TotalSellout     float64            `json:"total_sellout" bson:"total_sellout"`
 	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt        time.Time          `json:"updated_at" bson:"updated_at"`
+
+	// Diisi kalau data berasal dari import file.
+	ImportSource `bson:",inline"`
 }
 
 type SelloutCreateRequest struct {
@@ -58,3 +61,30 @@ type SelloutListResponse struct {
 	PerPage    int       `json:"per_page"`
 	TotalPages int       `json:"total_pages"`
 }
+
+// SelloutSummary adalah total sellout satu kelompok (mis. per region, cabang, bulan, atau colorist).
+// Field pengelompokan yang tidak dipakai dibiarkan kosong.
+type SelloutSummary struct {
+	Tahun         int     `json:"tahun,omitempty" bson:"tahun"`
+	Bulan         int     `json:"bulan,omitempty" bson:"bulan"`
+	Reg           string  `json:"reg,omitempty" bson:"reg"`
+	Cabang        string  `json:"cabang,omitempty" bson:"cabang"`
+	NoReg         string  `json:"no_reg,omitempty" bson:"no_reg"`
+	NamaColorist  string  `json:"nama_colorist,omitempty" bson:"nama_colorist"`
+	Colorists     int     `json:"colorists" bson:"colorists"`
+	Outlets       int     `json:"outlets" bson:"outlets"`
+	TargetSellout float64 `json:"target_sellout" bson:"target_sellout"`
+	SelloutTT     float64 `json:"sellout_tt" bson:"sellout_tt"`
+	SelloutRM     float64 `json:"sellout_rm" bson:"sellout_rm"`
+	Primafix      float64 `json:"primafix" bson:"primafix"`
+	TotalSellout  float64 `json:"total_sellout" bson:"total_sellout"`
+}
+
+// Achievement mengembalikan pencapaian total sellout terhadap target (1 = 100%).
+// ok bernilai false kalau targetnya kosong.
+func (s SelloutSummary) Achievement() (value float64, ok bool) {
+	if s.TargetSellout <= 0 {
+		return 0, false
+	}
+	return s.TotalSellout / s.TargetSellout, true
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Session mewakili satu login (satu device/browser). Refresh token hanya disimpan
+// dalam bentuk hash dan dirotasi setiap kali dipakai.
+type Session struct {
+	ID               primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserID           primitive.ObjectID `json:"user_id" bson:"user_id"`
+	Username         string             `json:"username" bson:"username"`
+	RefreshTokenHash string             `json:"-" bson:"refresh_token_hash"`
+	UserAgent        string             `json:"user_agent" bson:"user_agent"`
+	IP               string             `json:"ip" bson:"ip"`
+	CreatedAt        time.Time          `json:"created_at" bson:"created_at"`
+	LastUsedAt       time.Time          `json:"last_used_at" bson:"last_used_at"`
+	ExpiresAt        time.Time          `json:"expires_at" bson:"expires_at"`
+	RevokedAt        *time.Time         `json:"revoked_at,omitempty" bson:"revoked_at,omitempty"`
+}
+
+// ClientInfo berisi metadata request yang dicatat bersama session.
+type ClientInfo struct {
+	UserAgent string
+	IP        string
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+const (
+	SigningKeyStatusActive   = "active"
+	SigningKeyStatusRetiring = "retiring"
+)
+
+// SigningKey adalah pasangan key untuk menandatangani JWT. Key yang sudah dirotasi
+// berstatus retiring dan masih dipakai untuk verifikasi sampai VerifyUntil.
+type SigningKey struct {
+	Kid         string     `json:"kid" bson:"_id"`
+	Algorithm   string     `json:"alg" bson:"alg"`
+	PrivateKey  string     `json:"-" bson:"private_key"`
+	PublicKey   string     `json:"public_key" bson:"public_key"`
+	Status      string     `json:"status" bson:"status"`
+	CreatedAt   time.Time  `json:"created_at" bson:"created_at"`
+	RetiredAt   *time.Time `json:"retired_at,omitempty" bson:"retired_at,omitempty"`
+	VerifyUntil *time.Time `json:"verify_until,omitempty" bson:"verify_until,omitempty"`
+}
+
+// JWK mengikuti RFC 7517 untuk key RSA (kty RSA), Ed25519 (kty OKP) dan,
+// untuk membaca JWKS identity provider, ECDSA (kty EC).
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
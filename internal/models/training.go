@@ -21,6 +21,9 @@ type Training struct {
 	Total                float64            `json:"total" bson:"total"`
 	CreatedAt            time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt            time.Time          `json:"updated_at" bson:"updated_at"`
+
+	// Diisi kalau data berasal dari import file.
+	ImportSource `bson:",inline"`
 }
 
 type TrainingCreateRequest struct {
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	UserStatusActive   = "active"
+	UserStatusDisabled = "disabled"
+)
+
+type User struct {
+	ID           primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Username     string             `json:"username" bson:"username"`
+	DisplayName  string             `json:"display_name" bson:"display_name"`
+	PasswordHash string             `json:"-" bson:"password_hash"`
+	Role         string             `json:"role" bson:"role"`
+	Status       string             `json:"status" bson:"status"`
+	Scope        DataScope          `json:"scope" bson:"scope"`
+	Provider     string             `json:"provider,omitempty" bson:"provider,omitempty"`
+	Subject      string             `json:"-" bson:"subject,omitempty"`
+	CreatedAt    time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+type UserCreateRequest struct {
+	Username    string    `json:"username" binding:"required,min=3,max=64"`
+	DisplayName string    `json:"display_name"`
+	Password    string    `json:"password" binding:"required,min=8,max=72"`
+	Role        string    `json:"role" binding:"omitempty,oneof=viewer editor importer admin"`
+	Scope       DataScope `json:"scope"`
+}
+
+type UserUpdateRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=viewer editor importer admin"`
+}
+
+type UserUpdateScopeRequest struct {
+	Scope DataScope `json:"scope"`
+}
+
+type UserResetPasswordRequest struct {
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+type UserListResponse struct {
+	Data       []User `json:"data"`
+	Total      int64  `json:"total"`
+	Page       int    `json:"page"`
+	PerPage    int    `json:"per_page"`
+	TotalPages int    `json:"total_pages"`
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, apiKey *models.APIKey) error
+	FindByHash(ctx context.Context, keyHash string) (*models.APIKey, error)
+	FindByUser(ctx context.Context, userID string) ([]models.APIKey, error)
+	Revoke(ctx context.Context, id string, userID string) error
+	TouchLastUsed(ctx context.Context, id primitive.ObjectID, ip, userAgent string) error
+}
+
+type apiKeyRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAPIKeyRepository(db *mongo.Database) APIKeyRepository {
+	r := &apiKeyRepository{
+		collection: db.Collection("api_keys"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "key_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+	})
+	if err != nil {
+		log.Printf("Warning: failed to create api_keys indexes: %v", err)
+	}
+
+	return r
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, apiKey *models.APIKey) error {
+	apiKey.ID = primitive.NewObjectID()
+	apiKey.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, apiKey)
+	return err
+}
+
+func (r *apiKeyRepository) FindByHash(ctx context.Context, keyHash string) (*models.APIKey, error) {
+	var apiKey models.APIKey
+	err := r.collection.FindOne(ctx, bson.M{"key_hash": keyHash}).Decode(&apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+func (r *apiKeyRepository) FindByUser(ctx context.Context, userID string) ([]models.APIKey, error) {
+	objectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": objectID}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	apiKeys := []models.APIKey{}
+	if err = cursor.All(ctx, &apiKeys); err != nil {
+		return nil, err
+	}
+
+	return apiKeys, nil
+}
+
+// Revoke mencabut API key milik userID. Key milik user lain dianggap tidak ditemukan.
+func (r *apiKeyRepository) Revoke(ctx context.Context, id string, userID string) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+	userObjectID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": objectID, "user_id": userObjectID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id primitive.ObjectID, ip, userAgent string) error {
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{
+		"$set": bson.M{
+			"last_used_at":         time.Now(),
+			"last_used_ip":         ip,
+			"last_used_user_agent": userAgent,
+		},
+	})
+	return err
+}
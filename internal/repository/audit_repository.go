@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuditRepository interface {
+	InsertMany(ctx context.Context, logs []models.AuditLog) error
+	Find(ctx context.Context, filter models.AuditLogFilter, page, perPage int) ([]models.AuditLog, int64, error)
+}
+
+type auditRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAuditRepository(db *mongo.Database) AuditRepository {
+	r := &auditRepository{
+		collection: db.Collection("audit_logs"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "entity", Value: 1}, {Key: "entity_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "actor.username", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+	})
+	if err != nil {
+		log.Printf("Warning: failed to create audit_logs indexes: %v", err)
+	}
+
+	return r
+}
+
+func (r *auditRepository) InsertMany(ctx context.Context, logs []models.AuditLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	docs := make([]interface{}, len(logs))
+	for i, entry := range logs {
+		entry.ID = primitive.NewObjectID()
+		entry.CreatedAt = now
+		docs[i] = entry
+	}
+
+	_, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	return err
+}
+
+func (r *auditRepository) Find(ctx context.Context, filter models.AuditLogFilter, page, perPage int) ([]models.AuditLog, int64, error) {
+	query := bson.M{}
+	if filter.Entity != "" {
+		query["entity"] = filter.Entity
+	}
+	if filter.EntityID != "" {
+		query["entity_id"] = filter.EntityID
+	}
+	if filter.Action != "" {
+		query["action"] = filter.Action
+	}
+	if filter.User != "" {
+		query["$or"] = bson.A{
+			bson.M{"actor.username": filter.User},
+			bson.M{"actor.user_id": filter.User},
+		}
+	}
+	if filter.From != nil || filter.To != nil {
+		createdAt := bson.M{}
+		if filter.From != nil {
+			createdAt["$gte"] = *filter.From
+		}
+		if filter.To != nil {
+			createdAt["$lt"] = *filter.To
+		}
+		query["created_at"] = createdAt
+	}
+
+	skip := (page - 1) * perPage
+
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(perPage)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var logs []models.AuditLog
+	if err = cursor.All(ctx, &logs); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return logs, total, nil
+}
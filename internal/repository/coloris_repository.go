@@ -18,17 +18,31 @@ type ColorisRepository interface {
 	Update(ctx context.Context, id string, coloris *models.Coloris) error
 	Delete(ctx context.Context, id string) error
 	InsertMany(ctx context.Context, colorisData []models.Coloris) error
-	FindWithFilters(ctx context.Context, filters bson.M, page, perPage int) ([]models.Coloris, int64, error)
+	FindWithFilters(ctx context.Context, filters bson.M, sort bson.D, page, perPage int) ([]models.Coloris, int64, error)
+	ForEach(ctx context.Context, filters bson.M, sort bson.D, fn func(*models.Coloris) error) error
+	FindByKeys(ctx context.Context, keys []bson.M) ([]models.Coloris, error)
+	ReplaceMany(ctx context.Context, items []models.Coloris) error
+	DeleteMatching(ctx context.Context, filter bson.M) ([]models.Coloris, error)
+	Restore(ctx context.Context, items []models.Coloris) ([]models.Coloris, error)
+	Distinct(ctx context.Context, field string) ([]string, error)
+	SummarizeScores(ctx context.Context, filters bson.M) ([]models.TestSummary, error)
 }
 
 type colorisRepository struct {
 	collection *mongo.Collection
 }
 
-func NewColorisRepository(db *mongo.Database) ColorisRepository {
-	return &colorisRepository{
+// NewColorisRepository membuat repository Coloris. keyFields adalah natural key yang
+// dipakai import untuk upsert, dan dijaga dengan unique index.
+func NewColorisRepository(db *mongo.Database, keyFields []string) ColorisRepository {
+	r := &colorisRepository{
 		collection: db.Collection("coloris"),
 	}
+
+	createNaturalKeyIndex(r.collection, keyFields)
+	createImportBatchIndex(r.collection)
+
+	return r
 }
 
 func (r *colorisRepository) Create(ctx context.Context, coloris *models.Coloris) error {
@@ -47,7 +61,7 @@ func (r *colorisRepository) FindByID(ctx context.Context, id string) (*models.Co
 	}
 
 	var coloris models.Coloris
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&coloris)
+	err = r.collection.FindOne(ctx, r.scoped(ctx, bson.M{"_id": objectID})).Decode(&coloris)
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +70,7 @@ func (r *colorisRepository) FindByID(ctx context.Context, id string) (*models.Co
 }
 
 func (r *colorisRepository) FindAll(ctx context.Context, page, perPage int) ([]models.Coloris, int64, error) {
+	filter := r.scoped(ctx, bson.M{})
 	skip := (page - 1) * perPage
 
 	findOptions := options.Find()
@@ -63,7 +78,7 @@ func (r *colorisRepository) FindAll(ctx context.Context, page, perPage int) ([]m
 	findOptions.SetLimit(int64(perPage))
 	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
 
-	cursor, err := r.collection.Find(ctx, bson.M{}, findOptions)
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -74,7 +89,7 @@ func (r *colorisRepository) FindAll(ctx context.Context, page, perPage int) ([]m
 		return nil, 0, err
 	}
 
-	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -89,26 +104,35 @@ func (r *colorisRepository) Update(ctx context.Context, id string, coloris *mode
 	}
 
 	coloris.UpdatedAt = time.Now()
+	coloris.ImportSource = models.ImportSource{}
 
 	update := bson.M{
 		"$set": bson.M{
-			"timestamp":                coloris.Timestamp,
-			"bulan":                    coloris.Bulan,
-			"region":                   coloris.Region,
-			"cabang":                   coloris.Cabang,
-			"materi":                   coloris.Materi,
-			"nama_atasan_langsung":     coloris.NamaAtasanLangsung,
-			"nama_toko":                coloris.NamaToko,
-			"nama_lengkap_sesuai_ktp":  coloris.NamaLengkapSesuaiKTP,
-			"nilai_pg":                 coloris.NilaiPG,
-			"nilai_akhir":              coloris.NilaiAkhir,
-			"total":                    coloris.Total,
-			"updated_at":               coloris.UpdatedAt,
+			"timestamp":               coloris.Timestamp,
+			"bulan":                   coloris.Bulan,
+			"region":                  coloris.Region,
+			"cabang":                  coloris.Cabang,
+			"materi":                  coloris.Materi,
+			"nama_atasan_langsung":    coloris.NamaAtasanLangsung,
+			"nama_toko":               coloris.NamaToko,
+			"nama_lengkap_sesuai_ktp": coloris.NamaLengkapSesuaiKTP,
+			"nilai_pg":                coloris.NilaiPG,
+			"nilai_akhir":             coloris.NilaiAkhir,
+			"total":                   coloris.Total,
+			"updated_at":              coloris.UpdatedAt,
 		},
+		"$unset": unsetImportSource,
 	}
 
-	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
-	return err
+	result, err := r.collection.UpdateOne(ctx, r.scoped(ctx, bson.M{"_id": objectID}), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
 }
 
 func (r *colorisRepository) Delete(ctx context.Context, id string) error {
@@ -117,8 +141,15 @@ func (r *colorisRepository) Delete(ctx context.Context, id string) error {
 		return err
 	}
 
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
-	return err
+	result, err := r.collection.DeleteOne(ctx, r.scoped(ctx, bson.M{"_id": objectID}))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
 }
 
 func (r *colorisRepository) InsertMany(ctx context.Context, colorisData []models.Coloris) error {
@@ -126,41 +157,73 @@ func (r *colorisRepository) InsertMany(ctx context.Context, colorisData []models
 		return nil
 	}
 
+	// ID diisi langsung di slice supaya pemanggil (mis. audit log) tahu ID yang tersimpan.
 	documents := make([]interface{}, len(colorisData))
-	for i, data := range colorisData {
-		data.ID = primitive.NewObjectID()
-		data.CreatedAt = time.Now()
-		data.UpdatedAt = time.Now()
-		documents[i] = data
+	for i := range colorisData {
+		colorisData[i].ID = primitive.NewObjectID()
+		colorisData[i].CreatedAt = time.Now()
+		colorisData[i].UpdatedAt = time.Now()
+		documents[i] = colorisData[i]
 	}
 
 	_, err := r.collection.InsertMany(ctx, documents)
 	return err
 }
 
-func (r *colorisRepository) FindWithFilters(ctx context.Context, filters bson.M, page, perPage int) ([]models.Coloris, int64, error) {
-	skip := (page - 1) * perPage
-
-	findOptions := options.Find()
-	findOptions.SetSkip(int64(skip))
-	findOptions.SetLimit(int64(perPage))
-	findOptions.SetSort(bson.D{{Key: "created_at", Value: -1}})
-
-	cursor, err := r.collection.Find(ctx, filters, findOptions)
-	if err != nil {
-		return nil, 0, err
+// FindWithFilters mengambil satu halaman data sesuai filter dan urutan. sort nil memakai
+// urutan bawaan.
+func (r *colorisRepository) FindWithFilters(ctx context.Context, filters bson.M, sort bson.D, page, perPage int) ([]models.Coloris, int64, error) {
+	if sort == nil {
+		sort = bson.D{{Key: "created_at", Value: -1}}
 	}
-	defer cursor.Close(ctx)
+	return findPage[models.Coloris](ctx, r.collection, r.scoped(ctx, filters), sort, page, perPage)
+}
 
-	var colorisData []models.Coloris
-	if err = cursor.All(ctx, &colorisData); err != nil {
-		return nil, 0, err
+// ForEach memanggil fn untuk setiap data yang cocok dengan filter, dibaca lewat cursor.
+// sort nil memakai urutan bawaan.
+func (r *colorisRepository) ForEach(ctx context.Context, filters bson.M, sort bson.D, fn func(*models.Coloris) error) error {
+	if sort == nil {
+		sort = bson.D{{Key: "created_at", Value: -1}}
 	}
+	return forEach(ctx, r.collection, r.scoped(ctx, filters), sort, fn)
+}
 
-	total, err := r.collection.CountDocuments(ctx, filters)
-	if err != nil {
-		return nil, 0, err
+func (r *colorisRepository) FindByKeys(ctx context.Context, keys []bson.M) ([]models.Coloris, error) {
+	return findByKeys[models.Coloris](ctx, r.collection, keys)
+}
+
+// ReplaceMany menimpa data yang sudah ada berdasarkan ID-nya, dipakai import mode upsert.
+func (r *colorisRepository) ReplaceMany(ctx context.Context, items []models.Coloris) error {
+	for i := range items {
+		items[i].UpdatedAt = time.Now()
 	}
+	return replaceByID(ctx, r.collection, items, colorisID)
+}
 
-	return colorisData, total, nil
+// DeleteMatching menghapus data yang cocok dengan filter dalam scope user.
+func (r *colorisRepository) DeleteMatching(ctx context.Context, filter bson.M) ([]models.Coloris, error) {
+	return deleteMatching(ctx, r.collection, r.scoped(ctx, filter), colorisID)
+}
+
+// Restore mengembalikan data versi sebelum import dan mengembalikan data yang berhasil disimpan.
+func (r *colorisRepository) Restore(ctx context.Context, items []models.Coloris) ([]models.Coloris, error) {
+	return restoreDocuments(ctx, r.collection, items)
+}
+
+// Distinct mengembalikan nilai unik sebuah field dalam scope user, mis. untuk dropdown.
+func (r *colorisRepository) Distinct(ctx context.Context, field string) ([]string, error) {
+	return distinctStrings(ctx, r.collection, field, r.scoped(ctx, bson.M{}))
+}
+
+// SummarizeScores meringkas nilai total per materi untuk data yang cocok dengan filter.
+func (r *colorisRepository) SummarizeScores(ctx context.Context, filters bson.M) ([]models.TestSummary, error) {
+	return summarizeScores(ctx, r.collection, r.scoped(ctx, filters), "materi", "total")
+}
+
+func (r *colorisRepository) scoped(ctx context.Context, filter bson.M) bson.M {
+	return scopeFilter(ctx, filter, "region", "cabang")
+}
+
+func colorisID(item *models.Coloris) primitive.ObjectID {
+	return item.ID
 }
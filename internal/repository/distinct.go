@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// distinctStrings mengembalikan nilai unik sebuah field teks yang tidak kosong, urut abjad.
+func distinctStrings(ctx context.Context, collection *mongo.Collection, field string, filter bson.M) ([]string, error) {
+	values, err := collection.Distinct(ctx, field, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if s, ok := value.(string); ok && strings.TrimSpace(s) != "" {
+			result = append(result, s)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
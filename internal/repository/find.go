@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// exportBatchSize adalah jumlah dokumen per batch cursor saat data dibaca satu per satu.
+const exportBatchSize = 500
+
+// findPage mengambil satu halaman data sesuai filter dan urutan beserta total datanya.
+func findPage[T any](ctx context.Context, collection *mongo.Collection, filter bson.M, sort bson.D, page, perPage int) ([]T, int64, error) {
+	opts := options.Find().
+		SetSort(sort).
+		SetSkip(int64((page - 1) * perPage)).
+		SetLimit(int64(perPage))
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var data []T
+	if err = cursor.All(ctx, &data); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, total, nil
+}
+
+// forEach membaca data sesuai filter dan urutan satu per satu dari cursor, sehingga
+// memori tidak bergantung pada jumlah data. Berhenti di error pertama dari fn.
+func forEach[T any](ctx context.Context, collection *mongo.Collection, filter bson.M, sort bson.D, fn func(*T) error) error {
+	opts := options.Find().SetSort(sort).SetBatchSize(exportBatchSize)
+
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var item T
+		if err := cursor.Decode(&item); err != nil {
+			return err
+		}
+		if err := fn(&item); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
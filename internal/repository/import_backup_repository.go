@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ImportBackupRepository menyimpan versi data sebelum ditimpa atau dihapus oleh import,
+// supaya satu batch import bisa dikembalikan.
+type ImportBackupRepository interface {
+	SaveMany(ctx context.Context, entity, batchID string, docs []interface{}) error
+	FindByBatch(ctx context.Context, entity, batchID string) ([]bson.Raw, error)
+}
+
+type importBackupRepository struct {
+	collection *mongo.Collection
+}
+
+func NewImportBackupRepository(db *mongo.Database) ImportBackupRepository {
+	r := &importBackupRepository{
+		collection: db.Collection("import_backups"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "entity", Value: 1}, {Key: "batch_id", Value: 1}},
+	})
+	if err != nil {
+		log.Printf("Warning: failed to create import_backups indexes: %v", err)
+	}
+
+	return r
+}
+
+func (r *importBackupRepository) SaveMany(ctx context.Context, entity, batchID string, docs []interface{}) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	backups := make([]interface{}, len(docs))
+	for i, doc := range docs {
+		backups[i] = models.ImportBackup{
+			ID:        primitive.NewObjectID(),
+			Entity:    entity,
+			BatchID:   batchID,
+			Document:  doc,
+			CreatedAt: now,
+		}
+	}
+
+	_, err := r.collection.InsertMany(ctx, backups)
+	return err
+}
+
+func (r *importBackupRepository) FindByBatch(ctx context.Context, entity, batchID string) ([]bson.Raw, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"entity": entity, "batch_id": batchID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var docs []bson.Raw
+	for cursor.Next(ctx) {
+		doc, ok := cursor.Current.Lookup("document").DocumentOK()
+		if !ok {
+			continue
+		}
+		// Buffer cursor bisa dipakai ulang, jadi dokumennya disalin.
+		docs = append(docs, append(bson.Raw(nil), doc...))
+	}
+
+	return docs, cursor.Err()
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// createImportBatchIndex membuat index import_batch_id untuk rollback satu batch import.
+func createImportBatchIndex(collection *mongo.Collection) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "import_batch_id", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	})
+	if err != nil {
+		log.Printf("Warning: failed to create import batch index on %s: %v", collection.Name(), err)
+	}
+}
+
+// unsetImportSource menghapus field asal import saat data diedit manual, supaya data itu
+// tidak lagi dianggap milik batch import dan tidak ikut terhapus saat batch di-rollback.
+var unsetImportSource = bson.M{
+	"import_batch_id":  "",
+	"import_file_name": "",
+	"import_checksum":  "",
+	"imported_by":      "",
+	"imported_at":      "",
+}
+
+// restoreDocuments menyimpan kembali dokumen lama apa adanya (termasuk _id). Dokumen yang
+// _id atau natural key-nya sudah dipakai data lain dilewati; yang berhasil dikembalikan.
+func restoreDocuments[T any](ctx context.Context, collection *mongo.Collection, docs []T) ([]T, error) {
+	if len(docs) == 0 {
+		return docs, nil
+	}
+
+	documents := make([]interface{}, len(docs))
+	for i := range docs {
+		documents[i] = docs[i]
+	}
+
+	_, err := collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return docs, nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return nil, err
+	}
+
+	skipped := map[int]bool{}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if !mongo.IsDuplicateKeyError(writeErr) {
+			return nil, err
+		}
+		skipped[writeErr.Index] = true
+	}
+
+	restored := make([]T, 0, len(docs)-len(skipped))
+	for i := range docs {
+		if !skipped[i] {
+			restored = append(restored, docs[i])
+		}
+	}
+
+	return restored, nil
+}
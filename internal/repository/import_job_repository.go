@@ -0,0 +1,258 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ImportJobRepository interface {
+	Create(ctx context.Context, job *models.ImportJob) error
+	FindByID(ctx context.Context, id string) (*models.ImportJob, error)
+	Find(ctx context.Context, filter models.ImportJobFilter, page, perPage int) ([]models.ImportJob, int64, error)
+	MarkRunning(ctx context.Context, id primitive.ObjectID) error
+	UpdateProgress(ctx context.Context, id primitive.ObjectID, progress models.ImportProgress) (cancelRequested bool, err error)
+	Finish(ctx context.Context, id primitive.ObjectID, status string, progress models.ImportProgress, report *models.ImportReport, errMessage string) error
+	RequestCancel(ctx context.Context, id string) (*models.ImportJob, error)
+	FailStale(ctx context.Context, before time.Time) (int64, error)
+	StartRollback(ctx context.Context, id primitive.ObjectID, rollback models.ImportRollback) (*models.ImportJob, error)
+	FinishRollback(ctx context.Context, id primitive.ObjectID, rollback models.ImportRollback) error
+}
+
+type importJobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewImportJobRepository(db *mongo.Database) ImportJobRepository {
+	r := &importJobRepository{
+		collection: db.Collection("import_jobs"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "created_by.user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "status", Value: 1}, {Key: "updated_at", Value: 1}}},
+		{Keys: bson.D{{Key: "created_at", Value: -1}}},
+	})
+	if err != nil {
+		log.Printf("Warning: failed to create import_jobs indexes: %v", err)
+	}
+
+	return r
+}
+
+func (r *importJobRepository) Create(ctx context.Context, job *models.ImportJob) error {
+	job.ID = primitive.NewObjectID()
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = job.CreatedAt
+
+	_, err := r.collection.InsertOne(ctx, job)
+	return err
+}
+
+func (r *importJobRepository) FindByID(ctx context.Context, id string) (*models.ImportJob, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var job models.ImportJob
+	if err := r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&job); err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (r *importJobRepository) Find(ctx context.Context, filter models.ImportJobFilter, page, perPage int) ([]models.ImportJob, int64, error) {
+	query := bson.M{}
+	if filter.Entity != "" {
+		query["entity"] = filter.Entity
+	}
+	if filter.Status != "" {
+		query["status"] = filter.Status
+	}
+	if filter.UserID != "" {
+		query["created_by.user_id"] = filter.UserID
+	}
+
+	skip := (page - 1) * perPage
+
+	// Laporan per baris bisa besar, jadi tidak ikut di daftar job.
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(perPage)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetProjection(bson.M{"report": 0})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []models.ImportJob
+	if err = cursor.All(ctx, &jobs); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return jobs, total, nil
+}
+
+func (r *importJobRepository) MarkRunning(ctx context.Context, id primitive.ObjectID) error {
+	now := time.Now()
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.ImportJobQueued},
+		bson.M{"$set": bson.M{"status": models.ImportJobRunning, "started_at": now, "updated_at": now}},
+	)
+	return err
+}
+
+// UpdateProgress menyimpan kemajuan job (sekaligus menjadi heartbeat) dan mengembalikan
+// apakah pembatalan sudah diminta. Mengembalikan mongo.ErrNoDocuments kalau job tidak lagi
+// berjalan, mis. sudah ditandai gagal oleh FailStale.
+func (r *importJobRepository) UpdateProgress(ctx context.Context, id primitive.ObjectID, progress models.ImportProgress) (bool, error) {
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"cancel_requested": 1})
+
+	var job models.ImportJob
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": id, "status": models.ImportJobRunning},
+		bson.M{"$set": bson.M{"progress": progress, "updated_at": time.Now()}},
+		opts,
+	).Decode(&job)
+	if err != nil {
+		return false, err
+	}
+
+	return job.CancelRequested, nil
+}
+
+// Finish menyimpan hasil akhir job yang belum selesai. Mengembalikan mongo.ErrNoDocuments
+// kalau job sudah selesai lebih dulu, supaya status dari FailStale tidak tertimpa.
+func (r *importJobRepository) Finish(ctx context.Context, id primitive.ObjectID, status string, progress models.ImportProgress, report *models.ImportReport, errMessage string) error {
+	now := time.Now()
+	set := bson.M{
+		"status":      status,
+		"progress":    progress,
+		"updated_at":  now,
+		"finished_at": now,
+	}
+	if report != nil {
+		set["report"] = report
+	}
+	if errMessage != "" {
+		set["error"] = errMessage
+	}
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": bson.M{"$in": bson.A{models.ImportJobQueued, models.ImportJobRunning}}},
+		bson.M{"$set": set},
+	)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// RequestCancel menandai job yang belum selesai untuk dibatalkan. Job berhenti di batas
+// batch berikutnya. Mengembalikan mongo.ErrNoDocuments kalau job tidak ada atau sudah selesai.
+func (r *importJobRepository) RequestCancel(ctx context.Context, id string) (*models.ImportJob, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"report": 0})
+
+	var job models.ImportJob
+	err = r.collection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objectID, "status": bson.M{"$in": bson.A{models.ImportJobQueued, models.ImportJobRunning}}},
+		bson.M{"$set": bson.M{"cancel_requested": true, "updated_at": time.Now()}},
+		opts,
+	).Decode(&job)
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+// FailStale menandai job yang tidak memberi kabar sejak before sebagai gagal, mis. karena
+// instance server mati di tengah proses.
+func (r *importJobRepository) FailStale(ctx context.Context, before time.Time) (int64, error) {
+	now := time.Now()
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{
+			"status":     bson.M{"$in": bson.A{models.ImportJobQueued, models.ImportJobRunning}},
+			"updated_at": bson.M{"$lt": before},
+		},
+		bson.M{"$set": bson.M{
+			"status":      models.ImportJobFailed,
+			"error":       "import stopped unexpectedly, the server may have been restarted",
+			"updated_at":  now,
+			"finished_at": now,
+		}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// StartRollback menandai job sedang di-rollback. Hanya job yang sudah selesai dan belum
+// di-rollback (atau rollback sebelumnya gagal) yang bisa diklaim, supaya dua rollback
+// tidak berjalan bersamaan. Mengembalikan mongo.ErrNoDocuments kalau tidak bisa diklaim.
+func (r *importJobRepository) StartRollback(ctx context.Context, id primitive.ObjectID, rollback models.ImportRollback) (*models.ImportJob, error) {
+	opts := options.FindOneAndUpdate().
+		SetReturnDocument(options.After).
+		SetProjection(bson.M{"report": 0})
+
+	var job models.ImportJob
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{
+			"_id":    id,
+			"status": bson.M{"$in": bson.A{models.ImportJobCompleted, models.ImportJobFailed, models.ImportJobCancelled}},
+			"$or": bson.A{
+				bson.M{"rollback": bson.M{"$exists": false}},
+				bson.M{"rollback.error": bson.M{"$exists": true}},
+			},
+		},
+		bson.M{"$set": bson.M{"rollback": rollback, "updated_at": time.Now()}},
+		opts,
+	).Decode(&job)
+	if err != nil {
+		return nil, err
+	}
+
+	return &job, nil
+}
+
+func (r *importJobRepository) FinishRollback(ctx context.Context, id primitive.ObjectID, rollback models.ImportRollback) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"rollback": rollback, "updated_at": time.Now()}},
+	)
+	return err
+}
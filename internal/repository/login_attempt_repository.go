@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// loginAttemptRetention adalah lama catatan login disimpan sebelum dihapus TTL index.
+const loginAttemptRetention = 90 * 24 * time.Hour
+
+type LoginAttemptRepository interface {
+	Create(ctx context.Context, attempt *models.LoginAttempt) error
+	FindByUsername(ctx context.Context, username string, page, perPage int) ([]models.LoginAttempt, int64, error)
+	FindThrottles(ctx context.Context, keys []string) ([]models.LoginThrottle, error)
+	IncrementFailure(ctx context.Context, key string, window time.Duration) (*models.LoginThrottle, error)
+	SetLockedUntil(ctx context.Context, key string, until time.Time) error
+	DeleteThrottle(ctx context.Context, key string) error
+}
+
+type loginAttemptRepository struct {
+	collection         *mongo.Collection
+	throttleCollection *mongo.Collection
+}
+
+func NewLoginAttemptRepository(db *mongo.Database) LoginAttemptRepository {
+	r := &loginAttemptRepository{
+		collection:         db.Collection("login_attempts"),
+		throttleCollection: db.Collection("login_throttles"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "username", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(loginAttemptRetention.Seconds()))},
+	})
+	if err != nil {
+		log.Printf("Warning: failed to create login_attempts indexes: %v", err)
+	}
+
+	_, err = r.throttleCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		log.Printf("Warning: failed to create login_throttles index: %v", err)
+	}
+
+	return r
+}
+
+func (r *loginAttemptRepository) Create(ctx context.Context, attempt *models.LoginAttempt) error {
+	attempt.ID = primitive.NewObjectID()
+	attempt.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, attempt)
+	return err
+}
+
+func (r *loginAttemptRepository) FindByUsername(ctx context.Context, username string, page, perPage int) ([]models.LoginAttempt, int64, error) {
+	filter := bson.M{"username": username}
+	skip := (page - 1) * perPage
+
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(perPage)).
+		SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var attempts []models.LoginAttempt
+	if err = cursor.All(ctx, &attempts); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return attempts, total, nil
+}
+
+func (r *loginAttemptRepository) FindThrottles(ctx context.Context, keys []string) ([]models.LoginThrottle, error) {
+	cursor, err := r.throttleCollection.Find(ctx, bson.M{"_id": bson.M{"$in": keys}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var throttles []models.LoginThrottle
+	if err = cursor.All(ctx, &throttles); err != nil {
+		return nil, err
+	}
+
+	return throttles, nil
+}
+
+// IncrementFailure menambah counter gagal secara atomik dan mengembalikan nilai terbarunya.
+// Counter otomatis hilang kalau tidak ada kegagalan baru selama window.
+func (r *loginAttemptRepository) IncrementFailure(ctx context.Context, key string, window time.Duration) (*models.LoginThrottle, error) {
+	now := time.Now()
+	update := bson.M{
+		"$inc": bson.M{"failures": 1},
+		"$set": bson.M{
+			"last_failure_at": now,
+			"expires_at":      now.Add(window),
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	var throttle models.LoginThrottle
+	err := r.throttleCollection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&throttle)
+	if err != nil {
+		return nil, err
+	}
+
+	return &throttle, nil
+}
+
+func (r *loginAttemptRepository) SetLockedUntil(ctx context.Context, key string, until time.Time) error {
+	_, err := r.throttleCollection.UpdateOne(ctx,
+		bson.M{"_id": key},
+		bson.M{"$max": bson.M{"locked_until": until, "expires_at": until}},
+	)
+	return err
+}
+
+func (r *loginAttemptRepository) DeleteThrottle(ctx context.Context, key string) error {
+	_, err := r.throttleCollection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
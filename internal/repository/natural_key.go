@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// keyLookupBatchSize membatasi jumlah kunci per query $or.
+const keyLookupBatchSize = 500
+
+// createNaturalKeyIndex membuat unique index untuk natural key import. Kalau collection
+// sudah berisi duplikat (mis. dari import ganda sebelumnya), index gagal dibuat dan
+// hanya dicatat sebagai warning sampai duplikatnya dibersihkan.
+func createNaturalKeyIndex(collection *mongo.Collection, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keys := bson.D{}
+	for _, field := range fields {
+		keys = append(keys, bson.E{Key: field, Value: 1})
+	}
+
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    keys,
+		Options: options.Index().SetUnique(true).SetName("natural_key"),
+	})
+	if err != nil {
+		log.Printf("Warning: failed to create natural key index on %s: %v", collection.Name(), err)
+	}
+}
+
+// findByKeys mencari dokumen yang cocok dengan salah satu filter kunci. Sengaja tidak
+// memakai scope user, supaya import bisa mendeteksi kunci yang dipakai data di luar scope.
+func findByKeys[T any](ctx context.Context, collection *mongo.Collection, keys []bson.M) ([]T, error) {
+	results := []T{}
+	for start := 0; start < len(keys); start += keyLookupBatchSize {
+		end := min(start+keyLookupBatchSize, len(keys))
+
+		or := make(bson.A, 0, end-start)
+		for _, key := range keys[start:end] {
+			or = append(or, key)
+		}
+
+		cursor, err := collection.Find(ctx, bson.M{"$or": or})
+		if err != nil {
+			return nil, err
+		}
+
+		var batch []T
+		err = cursor.All(ctx, &batch)
+		cursor.Close(ctx)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, batch...)
+	}
+
+	return results, nil
+}
+
+// replaceByID menimpa dokumen berdasarkan _id dalam satu bulk write.
+func replaceByID[T any](ctx context.Context, collection *mongo.Collection, docs []T, id func(*T) primitive.ObjectID) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, len(docs))
+	for i := range docs {
+		writes[i] = mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": id(&docs[i])}).
+			SetReplacement(docs[i])
+	}
+
+	_, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// deleteMatching menghapus semua dokumen yang cocok dengan filter dan mengembalikan
+// dokumen yang terhapus (untuk audit log).
+func deleteMatching[T any](ctx context.Context, collection *mongo.Collection, filter bson.M, id func(*T) primitive.ObjectID) ([]T, error) {
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []T
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return docs, nil
+	}
+
+	ids := make(bson.A, len(docs))
+	for i := range docs {
+		ids[i] = id(&docs[i])
+	}
+
+	if _, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}}); err != nil {
+		return nil, err
+	}
+
+	return docs, nil
+}
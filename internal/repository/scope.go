@@ -0,0 +1,37 @@
+package repository
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/auth"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// scopeFilter menggabungkan filter dengan data scope user dari context.
+// regionField dan cabangField adalah nama field di collection yang bersangkutan.
+func scopeFilter(ctx context.Context, filter bson.M, regionField, cabangField string) bson.M {
+	scope := auth.ScopeFromContext(ctx)
+	if scope.IsEmpty() {
+		return filter
+	}
+
+	conditions := bson.M{}
+	if scope.Region != "" {
+		conditions[regionField] = exactMatch(scope.Region)
+	}
+	if scope.Cabang != "" {
+		conditions[cabangField] = exactMatch(scope.Cabang)
+	}
+
+	if len(filter) == 0 {
+		return conditions
+	}
+
+	return bson.M{"$and": bson.A{filter, conditions}}
+}
+
+// exactMatch mencocokkan nilai secara utuh tanpa memperhatikan huruf besar/kecil.
+func exactMatch(value string) bson.M {
+	return bson.M{"$regex": "^" + regexp.QuoteMeta(value) + "$", "$options": "i"}
+}
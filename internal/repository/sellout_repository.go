@@ -18,20 +18,36 @@ type SelloutRepository interface {
 	Update(ctx context.Context, id string, sellout *models.Sellout) error
 	Delete(ctx context.Context, id string) error
 	InsertMany(ctx context.Context, sellouts []models.Sellout) error
-	FindWithFilters(ctx context.Context, filters bson.M, page, perPage int) ([]models.Sellout, int64, error)
+	FindWithFilters(ctx context.Context, filters bson.M, sort bson.D, page, perPage int) ([]models.Sellout, int64, error)
+	ForEach(ctx context.Context, filters bson.M, sort bson.D, fn func(*models.Sellout) error) error
+	FindByKeys(ctx context.Context, keys []bson.M) ([]models.Sellout, error)
+	ReplaceMany(ctx context.Context, items []models.Sellout) error
+	DeleteMatching(ctx context.Context, filter bson.M) ([]models.Sellout, error)
+	Restore(ctx context.Context, items []models.Sellout) ([]models.Sellout, error)
+	Distinct(ctx context.Context, field string) ([]string, error)
+	Summarize(ctx context.Context, filters bson.M, groupBy ...string) ([]models.SelloutSummary, error)
+	TopColorists(ctx context.Context, filters bson.M, limit int) ([]models.SelloutSummary, error)
 }
 
 type selloutRepository struct {
 	collection *mongo.Collection
 }
 
-func NewSelloutRepository(db *mongo.Database) SelloutRepository {
-	return &selloutRepository{
+// NewSelloutRepository membuat repository Sellout. keyFields adalah natural key yang
+// dipakai import untuk upsert, dan dijaga dengan unique index.
+func NewSelloutRepository(db *mongo.Database, keyFields []string) SelloutRepository {
+	r := &selloutRepository{
 		collection: db.Collection("sellouts"),
 	}
+
+	createNaturalKeyIndex(r.collection, keyFields)
+	createImportBatchIndex(r.collection)
+
+	return r
 }
 
 func (r *selloutRepository) Create(ctx context.Context, sellout *models.Sellout) error {
+	sellout.ID = primitive.NewObjectID()
 	sellout.CreatedAt = time.Now()
 	sellout.UpdatedAt = time.Now()
 
@@ -46,7 +62,7 @@ func (r *selloutRepository) FindByID(ctx context.Context, id string) (*models.Se
 	}
 
 	var sellout models.Sellout
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&sellout)
+	err = r.collection.FindOne(ctx, r.scoped(ctx, bson.M{"_id": objectID})).Decode(&sellout)
 	if err != nil {
 		return nil, err
 	}
@@ -55,6 +71,7 @@ func (r *selloutRepository) FindByID(ctx context.Context, id string) (*models.Se
 }
 
 func (r *selloutRepository) FindAll(ctx context.Context, page, perPage int) ([]models.Sellout, int64, error) {
+	filter := r.scoped(ctx, bson.M{})
 	skip := (page - 1) * perPage
 
 	opts := options.Find().
@@ -62,7 +79,7 @@ func (r *selloutRepository) FindAll(ctx context.Context, page, perPage int) ([]m
 		SetLimit(int64(perPage)).
 		SetSort(bson.D{{Key: "tahun", Value: -1}, {Key: "bulan", Value: -1}})
 
-	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -73,7 +90,7 @@ func (r *selloutRepository) FindAll(ctx context.Context, page, perPage int) ([]m
 		return nil, 0, err
 	}
 
-	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -88,6 +105,7 @@ func (r *selloutRepository) Update(ctx context.Context, id string, sellout *mode
 	}
 
 	sellout.UpdatedAt = time.Now()
+	sellout.ImportSource = models.ImportSource{}
 
 	update := bson.M{
 		"$set": bson.M{
@@ -111,10 +129,18 @@ func (r *selloutRepository) Update(ctx context.Context, id string, sellout *mode
 			"total_sellout":     sellout.TotalSellout,
 			"updated_at":        sellout.UpdatedAt,
 		},
+		"$unset": unsetImportSource,
 	}
 
-	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
-	return err
+	result, err := r.collection.UpdateOne(ctx, r.scoped(ctx, bson.M{"_id": objectID}), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
 }
 
 func (r *selloutRepository) Delete(ctx context.Context, id string) error {
@@ -123,45 +149,152 @@ func (r *selloutRepository) Delete(ctx context.Context, id string) error {
 		return err
 	}
 
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
-	return err
+	result, err := r.collection.DeleteOne(ctx, r.scoped(ctx, bson.M{"_id": objectID}))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
 }
 
 func (r *selloutRepository) InsertMany(ctx context.Context, sellouts []models.Sellout) error {
+	// ID diisi langsung di slice supaya pemanggil (mis. audit log) tahu ID yang tersimpan.
 	docs := make([]interface{}, len(sellouts))
-	for i, sellout := range sellouts {
-		sellout.CreatedAt = time.Now()
-		sellout.UpdatedAt = time.Now()
-		docs[i] = sellout
+	for i := range sellouts {
+		sellouts[i].ID = primitive.NewObjectID()
+		sellouts[i].CreatedAt = time.Now()
+		sellouts[i].UpdatedAt = time.Now()
+		docs[i] = sellouts[i]
 	}
 
 	_, err := r.collection.InsertMany(ctx, docs)
 	return err
 }
 
-func (r *selloutRepository) FindWithFilters(ctx context.Context, filters bson.M, page, perPage int) ([]models.Sellout, int64, error) {
-	skip := (page - 1) * perPage
+// FindWithFilters mengambil satu halaman data sesuai filter dan urutan. sort nil memakai
+// urutan bawaan.
+func (r *selloutRepository) FindWithFilters(ctx context.Context, filters bson.M, sort bson.D, page, perPage int) ([]models.Sellout, int64, error) {
+	if sort == nil {
+		sort = bson.D{{Key: "tahun", Value: -1}, {Key: "bulan", Value: -1}}
+	}
+	return findPage[models.Sellout](ctx, r.collection, r.scoped(ctx, filters), sort, page, perPage)
+}
 
-	opts := options.Find().
-		SetSkip(int64(skip)).
-		SetLimit(int64(perPage)).
-		SetSort(bson.D{{Key: "tahun", Value: -1}, {Key: "bulan", Value: -1}})
+// ForEach memanggil fn untuk setiap data yang cocok dengan filter, dibaca lewat cursor.
+// sort nil memakai urutan bawaan.
+func (r *selloutRepository) ForEach(ctx context.Context, filters bson.M, sort bson.D, fn func(*models.Sellout) error) error {
+	if sort == nil {
+		sort = bson.D{{Key: "tahun", Value: -1}, {Key: "bulan", Value: -1}}
+	}
+	return forEach(ctx, r.collection, r.scoped(ctx, filters), sort, fn)
+}
 
-	cursor, err := r.collection.Find(ctx, filters, opts)
-	if err != nil {
-		return nil, 0, err
+func (r *selloutRepository) FindByKeys(ctx context.Context, keys []bson.M) ([]models.Sellout, error) {
+	return findByKeys[models.Sellout](ctx, r.collection, keys)
+}
+
+// ReplaceMany menimpa data yang sudah ada berdasarkan ID-nya, dipakai import mode upsert.
+func (r *selloutRepository) ReplaceMany(ctx context.Context, items []models.Sellout) error {
+	for i := range items {
+		items[i].UpdatedAt = time.Now()
 	}
-	defer cursor.Close(ctx)
+	return replaceByID(ctx, r.collection, items, selloutID)
+}
 
-	var sellouts []models.Sellout
-	if err = cursor.All(ctx, &sellouts); err != nil {
-		return nil, 0, err
+// DeleteMatching menghapus data yang cocok dengan filter dalam scope user.
+func (r *selloutRepository) DeleteMatching(ctx context.Context, filter bson.M) ([]models.Sellout, error) {
+	return deleteMatching(ctx, r.collection, r.scoped(ctx, filter), selloutID)
+}
+
+// Restore mengembalikan data versi sebelum import dan mengembalikan data yang berhasil disimpan.
+func (r *selloutRepository) Restore(ctx context.Context, items []models.Sellout) ([]models.Sellout, error) {
+	return restoreDocuments(ctx, r.collection, items)
+}
+
+// Distinct mengembalikan nilai unik sebuah field dalam scope user, mis. untuk dropdown.
+func (r *selloutRepository) Distinct(ctx context.Context, field string) ([]string, error) {
+	return distinctStrings(ctx, r.collection, field, r.scoped(ctx, bson.M{}))
+}
+
+// Summarize menjumlahkan target dan sellout data yang cocok dengan filter, dikelompokkan
+// per field groupBy (mis. "reg", "cabang") dan diurutkan sesuai field tersebut. Tanpa
+// groupBy hasilnya satu baris total. Colorist dan outlet dihitung unik per kelompok.
+func (r *selloutRepository) Summarize(ctx context.Context, filters bson.M, groupBy ...string) ([]models.SelloutSummary, error) {
+	sort := bson.D{}
+	for _, field := range groupBy {
+		sort = append(sort, bson.E{Key: field, Value: 1})
+	}
+	return r.summarize(ctx, filters, groupBy, sort, 0)
+}
+
+// TopColorists mengembalikan colorist dengan total sellout terbesar, maksimal limit orang.
+func (r *selloutRepository) TopColorists(ctx context.Context, filters bson.M, limit int) ([]models.SelloutSummary, error) {
+	sort := bson.D{{Key: "total_sellout", Value: -1}, {Key: "nama_colorist", Value: 1}}
+	return r.summarize(ctx, filters, []string{"no_reg", "nama_colorist", "cabang"}, sort, limit)
+}
+
+func (r *selloutRepository) summarize(ctx context.Context, filters bson.M, groupBy []string, sort bson.D, limit int) ([]models.SelloutSummary, error) {
+	id := bson.D{}
+	project := bson.D{{Key: "_id", Value: 0}}
+	for _, field := range groupBy {
+		id = append(id, bson.E{Key: field, Value: "$" + field})
+		project = append(project, bson.E{Key: field, Value: "$_id." + field})
+	}
+	var groupID interface{}
+	if len(id) > 0 {
+		groupID = id
 	}
+	project = append(project,
+		bson.E{Key: "colorists", Value: bson.M{"$size": "$colorists"}},
+		bson.E{Key: "outlets", Value: bson.M{"$size": "$outlets"}},
+		bson.E{Key: "target_sellout", Value: 1},
+		bson.E{Key: "sellout_tt", Value: 1},
+		bson.E{Key: "sellout_rm", Value: 1},
+		bson.E{Key: "primafix", Value: 1},
+		bson.E{Key: "total_sellout", Value: 1},
+	)
 
-	total, err := r.collection.CountDocuments(ctx, filters)
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: r.scoped(ctx, filters)}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: groupID},
+			{Key: "colorists", Value: bson.M{"$addToSet": "$no_reg"}},
+			{Key: "outlets", Value: bson.M{"$addToSet": "$outlet"}},
+			{Key: "target_sellout", Value: bson.M{"$sum": "$target_sellout"}},
+			{Key: "sellout_tt", Value: bson.M{"$sum": "$sellout_tt"}},
+			{Key: "sellout_rm", Value: bson.M{"$sum": "$sellout_rm"}},
+			{Key: "primafix", Value: bson.M{"$sum": "$primafix"}},
+			{Key: "total_sellout", Value: bson.M{"$sum": "$total_sellout"}},
+		}}},
+		{{Key: "$project", Value: project}},
+	}
+	if len(sort) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: sort}})
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
 	if err != nil {
-		return nil, 0, err
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var summary []models.SelloutSummary
+	if err := cursor.All(ctx, &summary); err != nil {
+		return nil, err
 	}
+	return summary, nil
+}
 
-	return sellouts, total, nil
+func (r *selloutRepository) scoped(ctx context.Context, filter bson.M) bson.M {
+	return scopeFilter(ctx, filter, "reg", "cabang")
+}
+
+func selloutID(item *models.Sellout) primitive.ObjectID {
+	return item.ID
 }
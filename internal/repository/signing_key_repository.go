@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SigningKeyRepository interface {
+	FindUsable(ctx context.Context) ([]models.SigningKey, error)
+	ReplaceActive(ctx context.Context, currentKid string, key *models.SigningKey, verifyUntil time.Time) error
+}
+
+type signingKeyRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSigningKeyRepository(db *mongo.Database) SigningKeyRepository {
+	r := &signingKeyRepository{
+		collection: db.Collection("signing_keys"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Key yang masa verifikasinya sudah lewat dihapus otomatis.
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "verify_until", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		log.Printf("Warning: failed to create signing_keys index: %v", err)
+	}
+
+	// Hanya boleh ada satu key aktif, supaya instance yang merotasi bersamaan tidak
+	// saling menimpa key aktif instance lain.
+	_, err = r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "status", Value: 1}},
+		Options: options.Index().SetName("single_active").SetUnique(true).
+			SetPartialFilterExpression(bson.M{"status": models.SigningKeyStatusActive}),
+	})
+	if err != nil {
+		log.Printf("Warning: failed to create signing_keys single_active index (more than one active key?): %v", err)
+	}
+
+	return r
+}
+
+// FindUsable mengembalikan key aktif dan key retiring yang masih dalam masa grace,
+// diurutkan dari yang terbaru.
+func (r *signingKeyRepository) FindUsable(ctx context.Context) ([]models.SigningKey, error) {
+	filter := bson.M{
+		"$or": bson.A{
+			bson.M{"status": models.SigningKeyStatusActive},
+			bson.M{"verify_until": bson.M{"$gt": time.Now()}},
+		},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var keys []models.SigningKey
+	if err = cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// ReplaceActive menjadikan key sebagai key aktif menggantikan currentKid (kosong kalau
+// belum ada key aktif). Key lama menjadi retiring sampai verifyUntil. Kalau key aktif
+// sudah diganti instance lain, hasilnya mongo.ErrNoDocuments (currentKid sudah tidak
+// aktif) atau duplicate key error (key aktif lain sudah dibuat) dan key tidak disimpan.
+func (r *signingKeyRepository) ReplaceActive(ctx context.Context, currentKid string, key *models.SigningKey, verifyUntil time.Time) error {
+	if currentKid != "" {
+		result, err := r.collection.UpdateOne(ctx,
+			bson.M{"_id": currentKid, "status": models.SigningKeyStatusActive},
+			bson.M{"$set": bson.M{
+				"status":       models.SigningKeyStatusRetiring,
+				"retired_at":   time.Now(),
+				"verify_until": verifyUntil,
+			}},
+		)
+		if err != nil {
+			return err
+		}
+		if result.MatchedCount == 0 {
+			return mongo.ErrNoDocuments
+		}
+	}
+
+	key.Status = models.SigningKeyStatusActive
+	key.CreatedAt = time.Now()
+	_, err := r.collection.InsertOne(ctx, key)
+	return err
+}
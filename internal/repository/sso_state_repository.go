@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SSOStateRepository interface {
+	Create(ctx context.Context, state *models.SSOState) error
+	Consume(ctx context.Context, state string) (*models.SSOState, error)
+}
+
+type ssoStateRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSSOStateRepository(db *mongo.Database) SSOStateRepository {
+	r := &ssoStateRepository{
+		collection: db.Collection("sso_states"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Alur login yang tidak pernah diselesaikan dihapus otomatis.
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		log.Printf("Warning: failed to create sso_states index: %v", err)
+	}
+
+	return r
+}
+
+func (r *ssoStateRepository) Create(ctx context.Context, state *models.SSOState) error {
+	state.CreatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, state)
+	return err
+}
+
+// Consume mengambil sekaligus menghapus state, jadi satu state hanya bisa dipakai sekali.
+// State yang sudah kedaluwarsa (tapi belum dihapus TTL) mengembalikan ErrNoDocuments.
+func (r *ssoStateRepository) Consume(ctx context.Context, state string) (*models.SSOState, error) {
+	var result models.SSOState
+	err := r.collection.FindOneAndDelete(ctx, bson.M{
+		"_id":        state,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&result)
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// summarizeScores menghitung jumlah peserta serta nilai rata-rata, tertinggi, dan terendah
+// per materi (groupField) dari field nilai scoreField, urut berdasarkan nama materi.
+func summarizeScores(ctx context.Context, collection *mongo.Collection, filter bson.M, groupField, scoreField string) ([]models.TestSummary, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$" + groupField},
+			{Key: "participants", Value: bson.M{"$sum": 1}},
+			{Key: "average", Value: bson.M{"$avg": "$" + scoreField}},
+			{Key: "highest", Value: bson.M{"$max": "$" + scoreField}},
+			{Key: "lowest", Value: bson.M{"$min": "$" + scoreField}},
+		}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "materi", Value: "$_id"},
+			{Key: "participants", Value: 1},
+			{Key: "average", Value: 1},
+			{Key: "highest", Value: 1},
+			{Key: "lowest", Value: 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "materi", Value: 1}}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var summary []models.TestSummary
+	if err := cursor.All(ctx, &summary); err != nil {
+		return nil, err
+	}
+	return summary, nil
+}
@@ -18,20 +18,35 @@ type TrainingRepository interface {
 	Update(ctx context.Context, id string, training *models.Training) error
 	Delete(ctx context.Context, id string) error
 	InsertMany(ctx context.Context, trainings []models.Training) error
-	FindWithFilters(ctx context.Context, filters bson.M, page, perPage int) ([]models.Training, int64, error)
+	FindWithFilters(ctx context.Context, filters bson.M, sort bson.D, page, perPage int) ([]models.Training, int64, error)
+	ForEach(ctx context.Context, filters bson.M, sort bson.D, fn func(*models.Training) error) error
+	FindByKeys(ctx context.Context, keys []bson.M) ([]models.Training, error)
+	ReplaceMany(ctx context.Context, items []models.Training) error
+	DeleteMatching(ctx context.Context, filter bson.M) ([]models.Training, error)
+	Restore(ctx context.Context, items []models.Training) ([]models.Training, error)
+	Distinct(ctx context.Context, field string) ([]string, error)
+	SummarizeScores(ctx context.Context, filters bson.M) ([]models.TestSummary, error)
 }
 
 type trainingRepository struct {
 	collection *mongo.Collection
 }
 
-func NewTrainingRepository(db *mongo.Database) TrainingRepository {
-	return &trainingRepository{
+// NewTrainingRepository membuat repository Training. keyFields adalah natural key yang
+// dipakai import untuk upsert, dan dijaga dengan unique index.
+func NewTrainingRepository(db *mongo.Database, keyFields []string) TrainingRepository {
+	r := &trainingRepository{
 		collection: db.Collection("trainings"),
 	}
+
+	createNaturalKeyIndex(r.collection, keyFields)
+	createImportBatchIndex(r.collection)
+
+	return r
 }
 
 func (r *trainingRepository) Create(ctx context.Context, training *models.Training) error {
+	training.ID = primitive.NewObjectID()
 	training.CreatedAt = time.Now()
 	training.UpdatedAt = time.Now()
 
@@ -46,7 +61,7 @@ func (r *trainingRepository) FindByID(ctx context.Context, id string) (*models.T
 	}
 
 	var training models.Training
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&training)
+	err = r.collection.FindOne(ctx, r.scoped(ctx, bson.M{"_id": objectID})).Decode(&training)
 	if err != nil {
 		return nil, err
 	}
@@ -55,6 +70,7 @@ func (r *trainingRepository) FindByID(ctx context.Context, id string) (*models.T
 }
 
 func (r *trainingRepository) FindAll(ctx context.Context, page, perPage int) ([]models.Training, int64, error) {
+	filter := r.scoped(ctx, bson.M{})
 	skip := (page - 1) * perPage
 
 	opts := options.Find().
@@ -62,7 +78,7 @@ func (r *trainingRepository) FindAll(ctx context.Context, page, perPage int) ([]
 		SetLimit(int64(perPage)).
 		SetSort(bson.D{{Key: "timestamp", Value: -1}})
 
-	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -73,7 +89,7 @@ func (r *trainingRepository) FindAll(ctx context.Context, page, perPage int) ([]
 		return nil, 0, err
 	}
 
-	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -88,26 +104,35 @@ func (r *trainingRepository) Update(ctx context.Context, id string, training *mo
 	}
 
 	training.UpdatedAt = time.Now()
+	training.ImportSource = models.ImportSource{}
 
 	update := bson.M{
 		"$set": bson.M{
-			"timestamp":              training.Timestamp,
-			"bulan":                  training.Bulan,
-			"region":                 training.Region,
-			"cabang_area":            training.CabangArea,
-			"nama_atasan_langsung":   training.NamaAtasanLangsung,
-			"materi_pelatihan":       training.MateriPelatihan,
+			"timestamp":               training.Timestamp,
+			"bulan":                   training.Bulan,
+			"region":                  training.Region,
+			"cabang_area":             training.CabangArea,
+			"nama_atasan_langsung":    training.NamaAtasanLangsung,
+			"materi_pelatihan":        training.MateriPelatihan,
 			"nama_lengkap_sesuai_ktp": training.NamaLengkapSesuaiKTP,
-			"jabatan":                training.Jabatan,
-			"total_nilai":            training.TotalNilai,
-			"nilai_essay":            training.NilaiEssay,
-			"total":                  training.Total,
-			"updated_at":             training.UpdatedAt,
+			"jabatan":                 training.Jabatan,
+			"total_nilai":             training.TotalNilai,
+			"nilai_essay":             training.NilaiEssay,
+			"total":                   training.Total,
+			"updated_at":              training.UpdatedAt,
 		},
+		"$unset": unsetImportSource,
 	}
 
-	_, err = r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
-	return err
+	result, err := r.collection.UpdateOne(ctx, r.scoped(ctx, bson.M{"_id": objectID}), update)
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
 }
 
 func (r *trainingRepository) Delete(ctx context.Context, id string) error {
@@ -116,45 +141,85 @@ func (r *trainingRepository) Delete(ctx context.Context, id string) error {
 		return err
 	}
 
-	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
-	return err
+	result, err := r.collection.DeleteOne(ctx, r.scoped(ctx, bson.M{"_id": objectID}))
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
 }
 
 func (r *trainingRepository) InsertMany(ctx context.Context, trainings []models.Training) error {
+	// ID diisi langsung di slice supaya pemanggil (mis. audit log) tahu ID yang tersimpan.
 	docs := make([]interface{}, len(trainings))
-	for i, training := range trainings {
-		training.CreatedAt = time.Now()
-		training.UpdatedAt = time.Now()
-		docs[i] = training
+	for i := range trainings {
+		trainings[i].ID = primitive.NewObjectID()
+		trainings[i].CreatedAt = time.Now()
+		trainings[i].UpdatedAt = time.Now()
+		docs[i] = trainings[i]
 	}
 
 	_, err := r.collection.InsertMany(ctx, docs)
 	return err
 }
 
-func (r *trainingRepository) FindWithFilters(ctx context.Context, filters bson.M, page, perPage int) ([]models.Training, int64, error) {
-	skip := (page - 1) * perPage
-
-	opts := options.Find().
-		SetSkip(int64(skip)).
-		SetLimit(int64(perPage)).
-		SetSort(bson.D{{Key: "timestamp", Value: -1}})
-
-	cursor, err := r.collection.Find(ctx, filters, opts)
-	if err != nil {
-		return nil, 0, err
+// FindWithFilters mengambil satu halaman data sesuai filter dan urutan. sort nil memakai
+// urutan bawaan.
+func (r *trainingRepository) FindWithFilters(ctx context.Context, filters bson.M, sort bson.D, page, perPage int) ([]models.Training, int64, error) {
+	if sort == nil {
+		sort = bson.D{{Key: "timestamp", Value: -1}}
 	}
-	defer cursor.Close(ctx)
+	return findPage[models.Training](ctx, r.collection, r.scoped(ctx, filters), sort, page, perPage)
+}
 
-	var trainings []models.Training
-	if err = cursor.All(ctx, &trainings); err != nil {
-		return nil, 0, err
+// ForEach memanggil fn untuk setiap data yang cocok dengan filter, dibaca lewat cursor.
+// sort nil memakai urutan bawaan.
+func (r *trainingRepository) ForEach(ctx context.Context, filters bson.M, sort bson.D, fn func(*models.Training) error) error {
+	if sort == nil {
+		sort = bson.D{{Key: "timestamp", Value: -1}}
 	}
+	return forEach(ctx, r.collection, r.scoped(ctx, filters), sort, fn)
+}
 
-	total, err := r.collection.CountDocuments(ctx, filters)
-	if err != nil {
-		return nil, 0, err
+func (r *trainingRepository) FindByKeys(ctx context.Context, keys []bson.M) ([]models.Training, error) {
+	return findByKeys[models.Training](ctx, r.collection, keys)
+}
+
+// ReplaceMany menimpa data yang sudah ada berdasarkan ID-nya, dipakai import mode upsert.
+func (r *trainingRepository) ReplaceMany(ctx context.Context, items []models.Training) error {
+	for i := range items {
+		items[i].UpdatedAt = time.Now()
 	}
+	return replaceByID(ctx, r.collection, items, trainingID)
+}
 
-	return trainings, total, nil
+// DeleteMatching menghapus data yang cocok dengan filter dalam scope user.
+func (r *trainingRepository) DeleteMatching(ctx context.Context, filter bson.M) ([]models.Training, error) {
+	return deleteMatching(ctx, r.collection, r.scoped(ctx, filter), trainingID)
+}
+
+// Restore mengembalikan data versi sebelum import dan mengembalikan data yang berhasil disimpan.
+func (r *trainingRepository) Restore(ctx context.Context, items []models.Training) ([]models.Training, error) {
+	return restoreDocuments(ctx, r.collection, items)
+}
+
+// Distinct mengembalikan nilai unik sebuah field dalam scope user, mis. untuk dropdown.
+func (r *trainingRepository) Distinct(ctx context.Context, field string) ([]string, error) {
+	return distinctStrings(ctx, r.collection, field, r.scoped(ctx, bson.M{}))
+}
+
+// SummarizeScores meringkas nilai total per materi_pelatihan untuk data yang cocok dengan filter.
+func (r *trainingRepository) SummarizeScores(ctx context.Context, filters bson.M) ([]models.TestSummary, error) {
+	return summarizeScores(ctx, r.collection, r.scoped(ctx, filters), "materi_pelatihan", "total")
+}
+
+func (r *trainingRepository) scoped(ctx context.Context, filter bson.M) bson.M {
+	return scopeFilter(ctx, filter, "region", "cabang_area")
+}
+
+func trainingID(item *models.Training) primitive.ObjectID {
+	return item.ID
 }
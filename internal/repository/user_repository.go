@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) error
+	FindByID(ctx context.Context, id string) (*models.User, error)
+	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	FindByExternalID(ctx context.Context, provider, subject string) (*models.User, error)
+	FindAll(ctx context.Context, page, perPage int) ([]models.User, int64, error)
+	UpdateStatus(ctx context.Context, id string, status string) error
+	UpdateRole(ctx context.Context, id string, role string) error
+	UpdateScope(ctx context.Context, id string, scope models.DataScope) error
+	UpdatePassword(ctx context.Context, id string, passwordHash string) error
+	LinkExternalIdentity(ctx context.Context, id string, provider, subject string) error
+	UpdateProfile(ctx context.Context, id string, displayName, role string, scope models.DataScope) error
+	Count(ctx context.Context) (int64, error)
+}
+
+type userRepository struct {
+	collection *mongo.Collection
+}
+
+func NewUserRepository(db *mongo.Database) UserRepository {
+	r := &userRepository{
+		collection: db.Collection("users"),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{
+			Keys:    bson.D{{Key: "username", Value: 1}},
+			Options: options.Index().SetUnique(true),
+		},
+		{
+			// Satu akun SSO (provider + subject) hanya boleh terhubung ke satu user lokal.
+			Keys: bson.D{{Key: "provider", Value: 1}, {Key: "subject", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"subject": bson.M{"$exists": true}}),
+		},
+	})
+	if err != nil {
+		log.Printf("Warning: failed to create users index: %v", err)
+	}
+
+	return r
+}
+
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	user.ID = primitive.NewObjectID()
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = time.Now()
+
+	_, err := r.collection.InsertOne(ctx, user)
+	return err
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id string) (*models.User, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	err := r.collection.FindOne(ctx, bson.M{"username": username}).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) FindByExternalID(ctx context.Context, provider, subject string) (*models.User, error) {
+	var user models.User
+	err := r.collection.FindOne(ctx, bson.M{"provider": provider, "subject": subject}).Decode(&user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *userRepository) FindAll(ctx context.Context, page, perPage int) ([]models.User, int64, error) {
+	skip := (page - 1) * perPage
+
+	opts := options.Find().
+		SetSkip(int64(skip)).
+		SetLimit(int64(perPage)).
+		SetSort(bson.D{{Key: "username", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var users []models.User
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
+
+func (r *userRepository) UpdateStatus(ctx context.Context, id string, status string) error {
+	return r.updateFields(ctx, id, bson.M{"status": status})
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, id string, role string) error {
+	return r.updateFields(ctx, id, bson.M{"role": role})
+}
+
+func (r *userRepository) UpdateScope(ctx context.Context, id string, scope models.DataScope) error {
+	return r.updateFields(ctx, id, bson.M{"scope": scope})
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, id string, passwordHash string) error {
+	return r.updateFields(ctx, id, bson.M{"password_hash": passwordHash})
+}
+
+func (r *userRepository) LinkExternalIdentity(ctx context.Context, id string, provider, subject string) error {
+	return r.updateFields(ctx, id, bson.M{"provider": provider, "subject": subject})
+}
+
+func (r *userRepository) UpdateProfile(ctx context.Context, id string, displayName, role string, scope models.DataScope) error {
+	return r.updateFields(ctx, id, bson.M{"display_name": displayName, "role": role, "scope": scope})
+}
+
+func (r *userRepository) Count(ctx context.Context) (int64, error) {
+	return r.collection.CountDocuments(ctx, bson.M{})
+}
+
+func (r *userRepository) updateFields(ctx context.Context, id string, fields bson.M) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return err
+	}
+
+	fields["updated_at"] = time.Now()
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": fields})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
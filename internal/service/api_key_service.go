@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
+	"github.com/web-dashboard-made-by-renz/backend/pkg/utils"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const apiKeyPrefix = "dsk_"
+
+var (
+	ErrInvalidAPIKey           = errors.New("invalid, expired or revoked API key")
+	ErrAPIKeyPermissionInvalid = errors.New("permission is not available for this role")
+)
+
+// APIKeyPrincipal adalah hasil autentikasi sebuah API key. Permissions sudah berupa
+// irisan antara permission key dan permission role user saat ini.
+type APIKeyPrincipal struct {
+	User        *models.User
+	APIKey      *models.APIKey
+	Permissions []string
+}
+
+// Principal mengubah hasil autentikasi API key menjadi identitas request.
+func (p *APIKeyPrincipal) Principal() *models.Principal {
+	return &models.Principal{
+		UserID:      p.User.ID.Hex(),
+		Username:    p.User.Username,
+		DisplayName: p.User.DisplayName,
+		Role:        p.User.Role,
+		Scope:       p.User.Scope,
+		Permissions: p.Permissions,
+		AuthMethod:  models.AuthMethodAPIKey,
+		APIKeyID:    p.APIKey.ID.Hex(),
+		ExpiresAt:   p.APIKey.ExpiresAt,
+	}
+}
+
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, userID string, req *models.APIKeyCreateRequest) (*models.APIKeyCreateResponse, error)
+	GetAPIKeys(ctx context.Context, userID string) ([]models.APIKey, error)
+	RevokeAPIKey(ctx context.Context, userID string, id string) error
+	Authenticate(ctx context.Context, key string, client models.ClientInfo) (*APIKeyPrincipal, error)
+}
+
+type apiKeyService struct {
+	repo     repository.APIKeyRepository
+	userRepo repository.UserRepository
+}
+
+func NewAPIKeyService(repo repository.APIKeyRepository, userRepo repository.UserRepository) APIKeyService {
+	return &apiKeyService{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, userID string, req *models.APIKeyCreateRequest) (*models.APIKeyCreateResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Key tidak boleh punya permission melebihi role pemiliknya.
+	permissions := req.Permissions
+	if len(permissions) == 0 {
+		permissions = models.RolePermissions[user.Role]
+	}
+	for _, p := range permissions {
+		if !models.HasPermission(user.Role, p) {
+			return nil, fmt.Errorf("%w: %s (role %s)", ErrAPIKeyPermissionInvalid, p, user.Role)
+		}
+	}
+
+	secret, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	key := apiKeyPrefix + secret
+
+	apiKey := &models.APIKey{
+		UserID:      user.ID,
+		Username:    user.Username,
+		Name:        strings.TrimSpace(req.Name),
+		Prefix:      key[:len(apiKeyPrefix)+6],
+		KeyHash:     utils.HashToken(key),
+		Permissions: permissions,
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	if err := s.repo.Create(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &models.APIKeyCreateResponse{
+		Key:    key,
+		APIKey: *apiKey,
+	}, nil
+}
+
+func (s *apiKeyService) GetAPIKeys(ctx context.Context, userID string) ([]models.APIKey, error) {
+	return s.repo.FindByUser(ctx, userID)
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, userID string, id string) error {
+	return s.repo.Revoke(ctx, id, userID)
+}
+
+func (s *apiKeyService) Authenticate(ctx context.Context, key string, client models.ClientInfo) (*APIKeyPrincipal, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.repo.FindByHash(ctx, utils.HashToken(key))
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	if apiKey.RevokedAt != nil || (apiKey.ExpiresAt != nil && time.Now().After(*apiKey.ExpiresAt)) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	user, err := s.userRepo.FindByID(ctx, apiKey.UserID.Hex())
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+	if user.Status != models.UserStatusActive {
+		return nil, ErrAccountDisabled
+	}
+
+	// Role user bisa saja diturunkan setelah key dibuat, jadi permission dihitung ulang.
+	var permissions []string
+	for _, p := range apiKey.Permissions {
+		if models.HasPermission(user.Role, p) {
+			permissions = append(permissions, p)
+		}
+	}
+
+	if err := s.repo.TouchLastUsed(ctx, apiKey.ID, client.IP, client.UserAgent); err != nil {
+		log.Printf("Warning: failed to update API key last used: %v", err)
+	}
+
+	return &APIKeyPrincipal{
+		User:        user,
+		APIKey:      apiKey,
+		Permissions: permissions,
+	}, nil
+}
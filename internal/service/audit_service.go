@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"log"
+	"reflect"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/auth"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// auditIgnoredFields tidak dicatat di diff karena selalu berubah atau bukan data bisnis.
+var auditIgnoredFields = map[string]bool{
+	"_id":        true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// AuditEntry adalah satu dokumen yang berubah. Before nil untuk create/import,
+// After nil untuk delete.
+type AuditEntry struct {
+	EntityID string
+	Before   interface{}
+	After    interface{}
+}
+
+type AuditService interface {
+	Record(ctx context.Context, entity, action string, entry AuditEntry)
+	RecordMany(ctx context.Context, entity, action string, entries []AuditEntry)
+	GetAuditLogs(ctx context.Context, filter models.AuditLogFilter, page, perPage int) (*models.AuditLogListResponse, error)
+}
+
+type auditService struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{
+		repo: repo,
+	}
+}
+
+func (s *auditService) Record(ctx context.Context, entity, action string, entry AuditEntry) {
+	s.RecordMany(ctx, entity, action, []AuditEntry{entry})
+}
+
+// RecordMany menulis audit log untuk setiap entry dengan actor, IP dan request ID
+// dari context. Perubahan datanya sudah tersimpan, jadi kegagalan menulis audit
+// hanya di-log dan tidak menggagalkan request.
+func (s *auditService) RecordMany(ctx context.Context, entity, action string, entries []AuditEntry) {
+	actor := actorFromContext(ctx)
+	info := auth.RequestInfoFromContext(ctx)
+
+	logs := make([]models.AuditLog, 0, len(entries))
+	for _, entry := range entries {
+		logs = append(logs, models.AuditLog{
+			Entity:    entity,
+			EntityID:  entry.EntityID,
+			Action:    action,
+			Actor:     actor,
+			Changes:   diffDocuments(entry.Before, entry.After),
+			IP:        info.IP,
+			UserAgent: info.UserAgent,
+			RequestID: info.RequestID,
+		})
+	}
+
+	// Context request bisa sudah dibatalkan (client putus) setelah data tersimpan.
+	if err := s.repo.InsertMany(context.WithoutCancel(ctx), logs); err != nil {
+		log.Printf("Warning: failed to write %d audit log(s) for %s %s: %v", len(logs), action, entity, err)
+	}
+}
+
+// actorFromContext mengambil user yang login dari context; tanpa principal dianggap "system".
+func actorFromContext(ctx context.Context) models.AuditActor {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal == nil {
+		return models.AuditActor{Username: "system"}
+	}
+
+	return models.AuditActor{
+		UserID:     principal.UserID,
+		Username:   principal.Username,
+		Role:       principal.Role,
+		AuthMethod: principal.AuthMethod,
+		APIKeyID:   principal.APIKeyID,
+	}
+}
+
+func (s *auditService) GetAuditLogs(ctx context.Context, filter models.AuditLogFilter, page, perPage int) (*models.AuditLogListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 10
+	}
+
+	data, total, err := s.repo.Find(ctx, filter, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage != 0 {
+		totalPages++
+	}
+
+	return &models.AuditLogListResponse{
+		Data:       data,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// diffDocuments membandingkan dua dokumen berdasarkan nama field BSON-nya dan
+// hanya mengembalikan field yang nilainya berbeda.
+func diffDocuments(before, after interface{}) map[string]models.AuditChange {
+	b := toDocument(before)
+	a := toDocument(after)
+
+	changes := map[string]models.AuditChange{}
+	for key, value := range b {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		if newValue, ok := a[key]; !ok || !reflect.DeepEqual(value, newValue) {
+			changes[key] = models.AuditChange{Before: value, After: a[key]}
+		}
+	}
+	for key, value := range a {
+		if auditIgnoredFields[key] {
+			continue
+		}
+		if _, ok := b[key]; !ok {
+			changes[key] = models.AuditChange{After: value}
+		}
+	}
+
+	return changes
+}
+
+func toDocument(v interface{}) bson.M {
+	doc := bson.M{}
+	if v == nil {
+		return doc
+	}
+
+	data, err := bson.Marshal(v)
+	if err != nil {
+		log.Printf("Warning: failed to encode audit document: %v", err)
+		return doc
+	}
+	if err := bson.Unmarshal(data, &doc); err != nil {
+		log.Printf("Warning: failed to decode audit document: %v", err)
+	}
+	return doc
+}
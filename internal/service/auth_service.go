@@ -1,82 +1,302 @@
 package service
 
 import (
+	"context"
+	"crypto/subtle"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
+	"github.com/web-dashboard-made-by-renz/backend/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
+var (
+	ErrInvalidToken        = errors.New("invalid or expired token")
+	ErrInvalidCredentials  = errors.New("invalid username or password")
+	ErrAccountDisabled     = errors.New("account is disabled")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+)
+
+// TokenConfig mengatur access token dan refresh token yang diterbitkan AuthService.
+type TokenConfig struct {
+	Issuer          string
+	Audience        string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// AccessTokenClaims adalah isi access token yang diterbitkan dan divalidasi AuthService.
+type AccessTokenClaims struct {
+	Username    string `json:"username"`
+	DisplayName string `json:"name,omitempty"`
+	Role        string `json:"role"`
+	Region      string `json:"region,omitempty"`
+	Cabang      string `json:"cabang,omitempty"`
+	SessionID   string `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+// Principal mengubah claims yang sudah tervalidasi menjadi identitas request.
+func (c *AccessTokenClaims) Principal() *models.Principal {
+	principal := &models.Principal{
+		UserID:      c.Subject,
+		Username:    c.Username,
+		DisplayName: c.DisplayName,
+		Role:        c.Role,
+		Scope:       models.DataScope{Region: c.Region, Cabang: c.Cabang},
+		Permissions: models.RolePermissions[c.Role],
+		AuthMethod:  models.AuthMethodJWT,
+		SessionID:   c.SessionID,
+		TokenID:     c.ID,
+	}
+	if c.ExpiresAt != nil {
+		principal.ExpiresAt = &c.ExpiresAt.Time
+	}
+	return principal
+}
+
 type AuthService interface {
-	Login(username, password string) (*models.LoginResponse, error)
-	ValidateToken(tokenString string) (*jwt.Token, error)
+	Login(ctx context.Context, username, password string, client models.ClientInfo) (*models.LoginResponse, error)
+	IssueSession(ctx context.Context, user *models.User, client models.ClientInfo) (*models.LoginResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*models.LoginResponse, error)
+	Logout(ctx context.Context, sessionID, jti string, tokenExpiresAt time.Time) error
+	LogoutAll(ctx context.Context, userID string) (int64, error)
+	ValidateToken(ctx context.Context, tokenString string) (*AccessTokenClaims, error)
 }
 
 type authService struct {
-	jwtSecret string
+	signingKeys SigningKeyService
+	tokenConfig TokenConfig
+	userRepo    repository.UserRepository
+	sessionRepo repository.SessionRepository
+	loginGuard  LoginGuard
 }
 
-func NewAuthService(jwtSecret string) AuthService {
+func NewAuthService(signingKeys SigningKeyService, tokenConfig TokenConfig, userRepo repository.UserRepository, sessionRepo repository.SessionRepository, loginGuard LoginGuard) AuthService {
 	return &authService{
-		jwtSecret: jwtSecret,
+		signingKeys: signingKeys,
+		tokenConfig: tokenConfig,
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		loginGuard:  loginGuard,
 	}
 }
 
-// Hardcoded admin credentials
-const (
-	AdminUsername = "admin"
-	AdminPassword = "admin123"
-)
+func (s *authService) Login(ctx context.Context, username, password string, client models.ClientInfo) (*models.LoginResponse, error) {
+	if err := s.loginGuard.Check(ctx, username, client.IP); err != nil {
+		if errors.Is(err, ErrLoginLocked) {
+			s.loginGuard.Record(ctx, username, client, models.LoginOutcomeLocked)
+		}
+		return nil, err
+	}
+
+	user, err := s.userRepo.FindByUsername(ctx, username)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
 
-func (s *authService) Login(username, password string) (*models.LoginResponse, error) {
 	// Validate credentials
-	if username != AdminUsername || password != AdminPassword {
-		return nil, errors.New("invalid username or password")
+	if user == nil || !utils.CheckPassword(user.PasswordHash, password) {
+		if err := s.loginGuard.Failure(ctx, username, client, models.LoginOutcomeInvalidCredentials); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
 	}
 
-	// Create user object
-	user := models.User{
-		Username: AdminUsername,
-		Role:     "admin",
+	if user.Status != models.UserStatusActive {
+		s.loginGuard.Record(ctx, username, client, models.LoginOutcomeAccountDisabled)
+		return nil, ErrAccountDisabled
 	}
 
-	// Create JWT token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"username": user.Username,
-		"role":     user.Role,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(), // Token valid for 24 hours
-		"iat":      time.Now().Unix(),
-	})
+	if err := s.loginGuard.Success(ctx, username, client); err != nil {
+		return nil, err
+	}
+
+	return s.IssueSession(ctx, user, client)
+}
 
-	// Sign token with secret
-	tokenString, err := token.SignedString([]byte(s.jwtSecret))
+// IssueSession membuat session baru untuk user yang sudah terautentikasi
+// (password atau SSO) dan menerbitkan access serta refresh token-nya.
+func (s *authService) IssueSession(ctx context.Context, user *models.User, client models.ClientInfo) (*models.LoginResponse, error) {
+	secret, err := utils.RandomToken(32)
 	if err != nil {
 		return nil, err
 	}
 
-	return &models.LoginResponse{
-		Token: tokenString,
-		User:  user,
-	}, nil
+	session := &models.Session{
+		UserID:           user.ID,
+		Username:         user.Username,
+		RefreshTokenHash: utils.HashToken(secret),
+		UserAgent:        client.UserAgent,
+		IP:               client.IP,
+		ExpiresAt:        time.Now().Add(s.tokenConfig.RefreshTokenTTL),
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, err
+	}
+
+	return s.buildResponse(ctx, user, session.ID.Hex(), secret)
 }
 
-func (s *authService) ValidateToken(tokenString string) (*jwt.Token, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("invalid signing method")
+// Refresh menukar refresh token dengan access token baru dan merotasi refresh token-nya.
+// Refresh token lama yang dipakai ulang dianggap bocor, sehingga session-nya langsung dicabut.
+func (s *authService) Refresh(ctx context.Context, refreshToken string) (*models.LoginResponse, error) {
+	sessionID, secret, ok := strings.Cut(refreshToken, ".")
+	if !ok || secret == "" {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, primitive.ErrInvalidHex) {
+			return nil, ErrInvalidRefreshToken
 		}
-		return []byte(s.jwtSecret), nil
-	})
+		return nil, err
+	}
+
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(secret)), []byte(session.RefreshTokenHash)) != 1 {
+		_ = s.sessionRepo.Revoke(ctx, sessionID)
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.FindByID(ctx, session.UserID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if user.Status != models.UserStatusActive {
+		_ = s.sessionRepo.Revoke(ctx, sessionID)
+		return nil, ErrAccountDisabled
+	}
+
+	newSecret, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
 
+	err = s.sessionRepo.Rotate(ctx, sessionID, session.RefreshTokenHash, utils.HashToken(newSecret), time.Now().Add(s.tokenConfig.RefreshTokenTTL))
 	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvalidRefreshToken
+		}
 		return nil, err
 	}
 
-	if !token.Valid {
-		return nil, errors.New("invalid token")
+	return s.buildResponse(ctx, user, sessionID, newSecret)
+}
+
+func (s *authService) Logout(ctx context.Context, sessionID, jti string, tokenExpiresAt time.Time) error {
+	if err := s.sessionRepo.Revoke(ctx, sessionID); err != nil {
+		return err
+	}
+
+	return s.sessionRepo.RevokeToken(ctx, jti, tokenExpiresAt)
+}
+
+func (s *authService) LogoutAll(ctx context.Context, userID string) (int64, error) {
+	return s.sessionRepo.RevokeAllForUser(ctx, userID)
+}
+
+// checkSession memastikan session dan jti milik sebuah access token belum dicabut.
+func (s *authService) checkSession(ctx context.Context, sessionID, jti string) error {
+	if sessionID == "" || jti == "" {
+		return ErrSessionRevoked
 	}
 
-	return token, nil
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) || errors.Is(err, primitive.ErrInvalidHex) {
+			return ErrSessionRevoked
+		}
+		return err
+	}
+
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return ErrSessionRevoked
+	}
+
+	revoked, err := s.sessionRepo.IsTokenRevoked(ctx, jti)
+	if err != nil {
+		return err
+	}
+	if revoked {
+		return ErrSessionRevoked
+	}
+
+	return nil
+}
+
+// ValidateToken adalah satu-satunya jalur validasi access token: tanda tangan, exp,
+// nbf, iat, iss dan aud, lalu status session dan jti. Token yang session atau jti-nya
+// sudah dicabut (logout, user dinonaktifkan) ditolak walaupun belum kedaluwarsa.
+func (s *authService) ValidateToken(ctx context.Context, tokenString string) (*AccessTokenClaims, error) {
+	claims := &AccessTokenClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.signingKeys.Keyfunc,
+		jwt.WithValidMethods([]string{SigningAlgRS256, SigningAlgEdDSA}),
+		jwt.WithIssuer(s.tokenConfig.Issuer),
+		jwt.WithAudience(s.tokenConfig.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if !token.Valid || claims.Subject == "" || claims.Username == "" {
+		return nil, ErrInvalidToken
+	}
+
+	if err := s.checkSession(ctx, claims.SessionID, claims.ID); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (s *authService) buildResponse(ctx context.Context, user *models.User, sessionID, refreshSecret string) (*models.LoginResponse, error) {
+	jti, err := utils.RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	// Create short-lived access token, signed with the current active key
+	tokenString, err := s.signingKeys.Sign(ctx, &AccessTokenClaims{
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		Role:        user.Role,
+		Region:      user.Scope.Region,
+		Cabang:      user.Scope.Cabang,
+		SessionID:   sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.tokenConfig.Issuer,
+			Subject:   user.ID.Hex(),
+			Audience:  jwt.ClaimStrings{s.tokenConfig.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.tokenConfig.AccessTokenTTL)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ID:        jti,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		Token:        tokenString,
+		RefreshToken: sessionID + "." + refreshSecret,
+		ExpiresIn:    int64(s.tokenConfig.AccessTokenTTL.Seconds()),
+		User:         *user,
+	}, nil
 }
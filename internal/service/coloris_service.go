@@ -3,7 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
-	"mime/multipart"
+	"io"
 
 	"github.com/web-dashboard-made-by-renz/backend/internal/models"
 	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
@@ -18,18 +18,28 @@ type ColorisService interface {
 	GetAllColoris(ctx context.Context, page, perPage int) (*models.ColorisListResponse, error)
 	UpdateColoris(ctx context.Context, id string, req *models.ColorisCreateRequest) error
 	DeleteColoris(ctx context.Context, id string) error
-	ImportFromExcel(ctx context.Context, file *multipart.FileHeader) (int, error)
-	ExportToExcel(ctx context.Context) (*excelize.File, error)
-	GetColorisWithFilters(ctx context.Context, filters map[string]string, page, perPage int) (*models.ColorisListResponse, error)
+	ImportFromExcel(ctx context.Context, file io.ReadSeeker, opts ImportOptions) (*models.ImportReport, error)
+	ImportSheets(ctx context.Context, file io.ReadSeeker, fileName string) (*models.ImportWorkbook, error)
+	ImportTemplate(ctx context.Context) (*excelize.File, error)
+	RollbackImport(ctx context.Context, batchID string, restore bool) (*models.ImportRollback, error)
+	ExportToExcel(ctx context.Context, query DataQuery, columns []string) (*excelize.File, error)
+	ExportRecords(ctx context.Context, query DataQuery, columns []string, open OpenRecordWriter) error
+	GetColorisWithFilters(ctx context.Context, query DataQuery, page, perPage int) (*models.ColorisListResponse, error)
 }
 
 type colorisService struct {
-	repo repository.ColorisRepository
+	repo         repository.ColorisRepository
+	audit        AuditService
+	backups      repository.ImportBackupRepository
+	importConfig ImportConfig
 }
 
-func NewColorisService(repo repository.ColorisRepository) ColorisService {
+func NewColorisService(repo repository.ColorisRepository, audit AuditService, backups repository.ImportBackupRepository, importConfig ImportConfig) ColorisService {
 	return &colorisService{
-		repo: repo,
+		repo:         repo,
+		audit:        audit,
+		backups:      backups,
+		importConfig: importConfig,
 	}
 }
 
@@ -53,7 +63,16 @@ func (s *colorisService) CreateColoris(ctx context.Context, req *models.ColorisC
 		Total:                req.Total,
 	}
 
-	return s.repo.Create(ctx, coloris)
+	if err := checkScope(ctx, coloris.Region, coloris.Cabang); err != nil {
+		return err
+	}
+
+	if err := s.repo.Create(ctx, coloris); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, models.AuditEntityColoris, models.AuditActionCreate, AuditEntry{EntityID: coloris.ID.Hex(), After: coloris})
+	return nil
 }
 
 func (s *colorisService) GetColorisById(ctx context.Context, id string) (*models.Coloris, error) {
@@ -107,58 +126,103 @@ func (s *colorisService) UpdateColoris(ctx context.Context, id string, req *mode
 		Total:                req.Total,
 	}
 
-	return s.repo.Update(ctx, id, coloris)
-}
-
-func (s *colorisService) DeleteColoris(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
-}
-
-func (s *colorisService) ImportFromExcel(ctx context.Context, fileHeader *multipart.FileHeader) (int, error) {
-	file, err := fileHeader.Open()
-	if err != nil {
-		return 0, fmt.Errorf("failed to open file: %v", err)
+	if err := checkScope(ctx, coloris.Region, coloris.Cabang); err != nil {
+		return err
 	}
-	defer file.Close()
 
-	excelFile, err := excelize.OpenReader(file)
+	before, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read Excel file: %v", err)
+		return err
 	}
-	defer excelFile.Close()
 
-	colorisData, err := utils.ParseExcelToColoris(excelFile)
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse Excel data: %v", err)
+	if err := s.repo.Update(ctx, id, coloris); err != nil {
+		return err
 	}
 
-	if len(colorisData) == 0 {
-		return 0, fmt.Errorf("no valid data found in Excel file")
+	// After dibaca ulang supaya audit mencatat dokumen yang benar-benar tersimpan,
+	// termasuk field yang tidak berasal dari request (created_at, asal import).
+	var after interface{} = coloris
+	if saved, err := s.repo.FindByID(ctx, id); err == nil {
+		after = saved
+	} else {
+		coloris.ID = before.ID
+		coloris.CreatedAt = before.CreatedAt
 	}
+	s.audit.Record(ctx, models.AuditEntityColoris, models.AuditActionUpdate, AuditEntry{EntityID: id, Before: before, After: after})
+	return nil
+}
 
-	err = s.repo.InsertMany(ctx, colorisData)
+func (s *colorisService) DeleteColoris(ctx context.Context, id string) error {
+	before, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return 0, fmt.Errorf("failed to insert data: %v", err)
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
 	}
 
-	return len(colorisData), nil
+	s.audit.Record(ctx, models.AuditEntityColoris, models.AuditActionDelete, AuditEntry{EntityID: id, Before: before})
+	return nil
 }
 
-func (s *colorisService) ExportToExcel(ctx context.Context) (*excelize.File, error) {
-	data, _, err := s.repo.FindAll(ctx, 1, 999999)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch data: %v", err)
-	}
+func (s *colorisService) ImportFromExcel(ctx context.Context, file io.ReadSeeker, opts ImportOptions) (*models.ImportReport, error) {
+	return runImport(ctx, s.importSpec(), file, opts)
+}
 
-	excelFile, err := utils.ExportColorisToExcel(data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Excel file: %v", err)
+func (s *colorisService) ImportSheets(ctx context.Context, file io.ReadSeeker, fileName string) (*models.ImportWorkbook, error) {
+	return listSheets(s.importSpec(), file, fileName)
+}
+
+func (s *colorisService) ImportTemplate(ctx context.Context) (*excelize.File, error) {
+	return importTemplate(ctx, s.importConfig.Columns, s.repo.Distinct, map[string]string{"Region": "region", "Cabang": "cabang", "Bulan": "bulan"})
+}
+
+func (s *colorisService) RollbackImport(ctx context.Context, batchID string, restore bool) (*models.ImportRollback, error) {
+	return rollbackImport(ctx, s.importSpec(), batchID, restore)
+}
+
+func (s *colorisService) importSpec() importSpec[models.Coloris] {
+	return importSpec[models.Coloris]{
+		entity: models.AuditEntityColoris,
+		config: s.importConfig,
+		parse: func(table *utils.ImportTable) (*utils.SheetReader[models.Coloris], error) {
+			return utils.ParseColoris(table, s.importConfig.Columns)
+		},
+		scopeColumn: "Region",
+		scope:       func(d *models.Coloris) (string, string) { return d.Region, d.Cabang },
+		id:          func(d *models.Coloris) string { return d.ID.Hex() },
+		keep: func(d, existing *models.Coloris) {
+			d.ID = existing.ID
+			d.CreatedAt = existing.CreatedAt
+		},
+		find: s.repo.FindByKeys,
+		count: func(ctx context.Context, filter bson.M) (int64, error) {
+			_, total, err := s.repo.FindWithFilters(ctx, filter, nil, 1, 1)
+			return total, err
+		},
+		insert:  s.repo.InsertMany,
+		update:  s.repo.ReplaceMany,
+		remove:  s.repo.DeleteMatching,
+		source:  func(d *models.Coloris) *models.ImportSource { return &d.ImportSource },
+		restore: s.repo.Restore,
+		backups: s.backups,
+		audit:   s.audit,
 	}
+}
 
-	return excelFile, nil
+// ExportToExcel mengekspor semua data yang cocok dengan query, dengan urutan yang sama
+// seperti list, memakai kolom terpilih (semua kolom kalau kosong).
+func (s *colorisService) ExportToExcel(ctx context.Context, query DataQuery, columns []string) (*excelize.File, error) {
+	return exportExcel(ctx, colorisQuery.with(s.importConfig.Columns), query, columns, "Coloris Data", s.repo.ForEach, utils.ColorisRow)
 }
 
-func (s *colorisService) GetColorisWithFilters(ctx context.Context, filters map[string]string, page, perPage int) (*models.ColorisListResponse, error) {
+// ExportRecords mengekspor data yang cocok dengan query ke CSV, NDJSON, atau JSON.
+func (s *colorisService) ExportRecords(ctx context.Context, query DataQuery, columns []string, open OpenRecordWriter) error {
+	return exportRecords(ctx, colorisQuery.with(s.importConfig.Columns), query, columns, open, s.repo.ForEach, utils.ColorisRow)
+}
+
+func (s *colorisService) GetColorisWithFilters(ctx context.Context, query DataQuery, page, perPage int) (*models.ColorisListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -166,14 +230,12 @@ func (s *colorisService) GetColorisWithFilters(ctx context.Context, filters map[
 		perPage = 10
 	}
 
-	bsonFilters := bson.M{}
-	for key, value := range filters {
-		if value != "" {
-			bsonFilters[key] = bson.M{"$regex": value, "$options": "i"}
-		}
+	filter, sort, err := colorisQuery.with(s.importConfig.Columns).build(query)
+	if err != nil {
+		return nil, err
 	}
 
-	data, total, err := s.repo.FindWithFilters(ctx, bsonFilters, page, perPage)
+	data, total, err := s.repo.FindWithFilters(ctx, filter, sort, page, perPage)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/web-dashboard-made-by-renz/backend/pkg/utils"
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Exporter adalah service yang datanya bisa diexport ke Excel, CSV, NDJSON, atau JSON.
+type Exporter interface {
+	ExportToExcel(ctx context.Context, query DataQuery, columns []string) (*excelize.File, error)
+	ExportRecords(ctx context.Context, query DataQuery, columns []string, open OpenRecordWriter) error
+}
+
+// ReportExporter adalah service yang juga bisa membuat laporan Excel dengan ringkasan dan grafik.
+type ReportExporter interface {
+	ExportReport(ctx context.Context, query DataQuery, columns []string) (*excelize.File, error)
+}
+
+// OpenRecordWriter dipanggil setelah query dan kolom valid, tepat sebelum baris pertama
+// ditulis, sehingga error validasi masih bisa dikirim sebagai response biasa.
+type OpenRecordWriter func(columns *utils.ExportColumns) (utils.RecordWriter, error)
+
+// exportExcel menulis hasil query ke satu sheet Excel.
+func exportExcel[T any](ctx context.Context, spec querySpec, query DataQuery, columns []string, sheetName string,
+	each func(context.Context, bson.M, bson.D, func(*T) error) error, row func(*T) []interface{}) (*excelize.File, error) {
+	filter, sort, selected, err := spec.prepare(query, columns)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := utils.NewExcelWriter(sheetName, selected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Excel file: %v", err)
+	}
+	return fillExcel(ctx, writer, filter, sort, each, row)
+}
+
+// fillExcel membaca data lewat cursor dan langsung menulisnya ke StreamWriter, jadi data
+// tidak pernah dimuat sekaligus ke memori. writer ditutup kalau terjadi error.
+func fillExcel[T any](ctx context.Context, writer *utils.ExcelWriter, filter bson.M, sort bson.D,
+	each func(context.Context, bson.M, bson.D, func(*T) error) error, row func(*T) []interface{}) (*excelize.File, error) {
+	err := each(ctx, filter, sort, func(data *T) error {
+		return writer.Write(row(data))
+	})
+	if err != nil {
+		writer.Close()
+		if errors.Is(err, utils.ErrExcelRowLimit) {
+			return nil, fmt.Errorf("%w: %v, persempit filter", ErrInvalidQuery, err)
+		}
+		return nil, fmt.Errorf("failed to fetch data: %v", err)
+	}
+
+	excelFile, err := writer.Finish()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Excel file: %v", err)
+	}
+	return excelFile, nil
+}
+
+// exportRecords menulis hasil query baris demi baris dari cursor ke writer yang dibuat
+// open, tanpa menyimpan data atau file di memori maupun disk.
+func exportRecords[T any](ctx context.Context, spec querySpec, query DataQuery, columns []string, open OpenRecordWriter,
+	each func(context.Context, bson.M, bson.D, func(*T) error) error, row func(*T) []interface{}) error {
+	filter, sort, selected, err := spec.prepare(query, columns)
+	if err != nil {
+		return err
+	}
+
+	writer, err := open(selected)
+	if err != nil {
+		return err
+	}
+	err = each(ctx, filter, sort, func(data *T) error {
+		return writer.Write(row(data))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to export data: %v", err)
+	}
+	return writer.Close()
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/auth"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
+	"github.com/web-dashboard-made-by-renz/backend/pkg/utils"
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// importJobStaleAfter adalah batas waktu tanpa heartbeat sebelum job yang masih
+// berjalan dianggap mati. Heartbeat dikirim selama validasi dan setiap selesai satu batch.
+const importJobStaleAfter = 5 * time.Minute
+
+var (
+	ErrImportCancelled     = errors.New("import cancelled")
+	ErrImportJobFinished   = errors.New("import job already finished")
+	ErrImportJobRunning    = errors.New("import job is still running")
+	ErrImportJobStopped    = errors.New("import job is no longer running")
+	ErrImportRolledBack    = errors.New("import batch already rolled back")
+	ErrUnknownImportEntity = errors.New("unknown import entity")
+)
+
+// Importer adalah service data yang bisa mengimport file Excel dan me-rollback satu batch import.
+type Importer interface {
+	ImportFromExcel(ctx context.Context, file io.ReadSeeker, opts ImportOptions) (*models.ImportReport, error)
+	ImportSheets(ctx context.Context, file io.ReadSeeker, fileName string) (*models.ImportWorkbook, error)
+	ImportTemplate(ctx context.Context) (*excelize.File, error)
+	RollbackImport(ctx context.Context, batchID string, restore bool) (*models.ImportRollback, error)
+}
+
+type ImportJobService interface {
+	Submit(ctx context.Context, entity string, file *multipart.FileHeader, opts ImportOptions) (*models.ImportJob, error)
+	GetJob(ctx context.Context, id string) (*models.ImportJob, error)
+	ListJobs(ctx context.Context, filter models.ImportJobFilter, page, perPage int) (*models.ImportJobListResponse, error)
+	Cancel(ctx context.Context, id string) (*models.ImportJob, error)
+	Rollback(ctx context.Context, id string, restore bool) (*models.ImportJob, error)
+}
+
+type importJobService struct {
+	repo      repository.ImportJobRepository
+	importers map[string]Importer
+}
+
+func NewImportJobService(repo repository.ImportJobRepository, importers map[string]Importer) ImportJobService {
+	return &importJobService{
+		repo:      repo,
+		importers: importers,
+	}
+}
+
+// Submit menyimpan job lalu memproses file di background. File upload disalin ke file
+// sementara milik job karena file upload dihapus setelah request selesai; salinannya
+// dihapus setelah job selesai. Isi file tidak pernah dimuat utuh ke memori.
+func (s *importJobService) Submit(ctx context.Context, entity string, fileHeader *multipart.FileHeader, opts ImportOptions) (*models.ImportJob, error) {
+	importer, ok := s.importers[entity]
+	if !ok {
+		return nil, ErrUnknownImportEntity
+	}
+	if opts.Mode == "" {
+		opts.Mode = models.ImportModeUpsert
+	}
+
+	path, checksum, err := spoolUpload(fileHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &models.ImportJob{
+		Entity:    entity,
+		Status:    models.ImportJobQueued,
+		Mode:      opts.Mode,
+		FileName:  fileHeader.Filename,
+		Checksum:  checksum,
+		CreatedBy: actorFromContext(ctx),
+	}
+	if opts.Sheet != (utils.SheetSelection{}) {
+		job.Sheet = opts.Sheet.String()
+	}
+	if err := s.repo.Create(ctx, job); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	// Context job tetap membawa user, scope dan request ID (untuk audit log),
+	// tetapi tidak ikut batal saat request HTTP selesai.
+	opts.Source = &models.ImportSource{
+		ImportBatchID:  job.ID.Hex(),
+		ImportFileName: job.FileName,
+		ImportChecksum: job.Checksum,
+		ImportedBy:     job.CreatedBy.Username,
+		ImportedAt:     &job.CreatedAt,
+	}
+	go s.run(context.WithoutCancel(ctx), job.ID, importer, path, opts)
+
+	return job, nil
+}
+
+func (s *importJobService) run(ctx context.Context, id primitive.ObjectID, importer Importer, path string, opts ImportOptions) {
+	var progress models.ImportProgress
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Import job %s panicked: %v", id.Hex(), r)
+			s.finish(ctx, id, models.ImportJobFailed, progress, nil, fmt.Sprintf("internal error: %v", r))
+		}
+	}()
+	defer os.Remove(path)
+
+	file, err := os.Open(path)
+	if err != nil {
+		s.finish(ctx, id, models.ImportJobFailed, progress, nil, fmt.Sprintf("failed to open uploaded file: %v", err))
+		return
+	}
+	defer file.Close()
+
+	if err := s.repo.MarkRunning(ctx, id); err != nil {
+		log.Printf("Warning: failed to mark import job %s as running: %v", id.Hex(), err)
+	}
+
+	opts.Progress = func(ctx context.Context, p models.ImportProgress) error {
+		progress = p
+		cancelRequested, err := s.repo.UpdateProgress(ctx, id, p)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			// Job sudah ditandai gagal (mis. dianggap mati oleh failStale) dan bisa saja
+			// sedang di-rollback, jadi tidak boleh ada batch lain yang ditulis.
+			return ErrImportJobStopped
+		}
+		if err != nil {
+			log.Printf("Warning: failed to update import job %s progress: %v", id.Hex(), err)
+			return nil
+		}
+		if cancelRequested {
+			return ErrImportCancelled
+		}
+		return nil
+	}
+
+	report, err := importer.ImportFromExcel(ctx, file, opts)
+	if errors.Is(err, ErrImportJobStopped) {
+		log.Printf("Import job %s stopped, it was finished by another process", id.Hex())
+		return
+	}
+
+	status := models.ImportJobCompleted
+	message := ""
+	switch {
+	case errors.Is(err, ErrImportCancelled):
+		status = models.ImportJobCancelled
+	case err != nil:
+		status = models.ImportJobFailed
+		message = err.Error()
+	}
+
+	s.finish(ctx, id, status, progress, report, message)
+}
+
+// spoolUpload menyalin file upload ke file sementara sambil menghitung checksum SHA-256-nya.
+func spoolUpload(fileHeader *multipart.FileHeader) (path, checksum string, err error) {
+	src, err := fileHeader.Open()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open file: %v", err)
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp("", "import-*"+filepath.Ext(fileHeader.Filename))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create temp file: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(dst.Name())
+		}
+	}()
+
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(dst, hash), src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read file: %v", err)
+	}
+
+	return dst.Name(), hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func (s *importJobService) finish(ctx context.Context, id primitive.ObjectID, status string, progress models.ImportProgress, report *models.ImportReport, message string) {
+	if err := s.repo.Finish(ctx, id, status, progress, report, message); err != nil {
+		log.Printf("Warning: failed to finish import job %s (%s): %v", id.Hex(), status, err)
+	}
+}
+
+func (s *importJobService) GetJob(ctx context.Context, id string) (*models.ImportJob, error) {
+	s.failStale(ctx)
+
+	job, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !canSeeJob(ctx, job) {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	return job, nil
+}
+
+func (s *importJobService) ListJobs(ctx context.Context, filter models.ImportJobFilter, page, perPage int) (*models.ImportJobListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 10
+	}
+
+	s.failStale(ctx)
+
+	if !canSeeAllJobs(ctx) {
+		filter.UserID = actorFromContext(ctx).UserID
+	}
+
+	data, total, err := s.repo.Find(ctx, filter, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage != 0 {
+		totalPages++
+	}
+
+	return &models.ImportJobListResponse{
+		Data:       data,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// Cancel meminta job berhenti. Batch yang sudah tersimpan tidak dibatalkan.
+func (s *importJobService) Cancel(ctx context.Context, id string) (*models.ImportJob, error) {
+	job, err := s.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job.IsFinished() {
+		return nil, ErrImportJobFinished
+	}
+
+	job, err = s.repo.RequestCancel(ctx, id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// Job selesai di antara pengecekan dan pembatalan.
+		return nil, ErrImportJobFinished
+	}
+	return job, err
+}
+
+// Rollback menghapus data yang masih tercatat dari batch import ini. Dengan restore, data
+// yang ditimpa atau dihapus oleh import dikembalikan ke versi sebelumnya.
+func (s *importJobService) Rollback(ctx context.Context, id string, restore bool) (*models.ImportJob, error) {
+	job, err := s.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !job.IsFinished() {
+		return nil, ErrImportJobRunning
+	}
+	if job.Rollback != nil && job.Rollback.Error == "" {
+		return nil, ErrImportRolledBack
+	}
+	importer, ok := s.importers[job.Entity]
+	if !ok {
+		return nil, ErrUnknownImportEntity
+	}
+
+	rollback := models.ImportRollback{
+		Restore:   restore,
+		By:        actorFromContext(ctx),
+		StartedAt: time.Now(),
+	}
+	job, err = s.repo.StartRollback(ctx, job.ID, rollback)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// Rollback lain sudah berjalan atau selesai lebih dulu.
+		return nil, ErrImportRolledBack
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Rollback tetap diselesaikan walaupun client memutus koneksi.
+	ctx = context.WithoutCancel(ctx)
+	result, rollbackErr := importer.RollbackImport(ctx, job.ID.Hex(), restore)
+	if result != nil {
+		rollback.Deleted = result.Deleted
+		rollback.Restored = result.Restored
+		rollback.Skipped = result.Skipped
+	}
+	if rollbackErr != nil {
+		rollback.Error = rollbackErr.Error()
+	}
+	finishedAt := time.Now()
+	rollback.FinishedAt = &finishedAt
+
+	if err := s.repo.FinishRollback(ctx, job.ID, rollback); err != nil {
+		log.Printf("Warning: failed to save rollback result of import job %s: %v", job.ID.Hex(), err)
+	}
+	job.Rollback = &rollback
+
+	return job, rollbackErr
+}
+
+func (s *importJobService) failStale(ctx context.Context) {
+	count, err := s.repo.FailStale(ctx, time.Now().Add(-importJobStaleAfter))
+	if err != nil {
+		log.Printf("Warning: failed to check stale import jobs: %v", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("Marked %d stale import job(s) as failed", count)
+	}
+}
+
+// canSeeAllJobs: admin (users:manage) melihat semua job, user lain hanya job miliknya.
+func canSeeAllJobs(ctx context.Context) bool {
+	principal := auth.PrincipalFromContext(ctx)
+	return principal != nil && slices.Contains(principal.Permissions, models.PermissionUsersManage)
+}
+
+func canSeeJob(ctx context.Context, job *models.ImportJob) bool {
+	return canSeeAllJobs(ctx) || job.CreatedBy.UserID == actorFromContext(ctx).UserID
+}
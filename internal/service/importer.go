@@ -0,0 +1,692 @@
+package service
+
+import (
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
+	"github.com/web-dashboard-made-by-renz/backend/pkg/utils"
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrNoValidImportRows = errors.New("no valid data found in Excel file")
+
+// ImportConfig mengatur import satu jenis data.
+type ImportConfig struct {
+	Columns *utils.ColumnSet
+	// Key adalah natural key (nama field BSON) untuk mengenali data yang sudah ada.
+	Key []string
+	// Period adalah field periode yang dihapus oleh mode replace, mis. tahun dan bulan.
+	Period []string
+}
+
+// NewImportConfig menambahkan alias kolom dan memastikan field Key dan Period ada di model T.
+func NewImportConfig[T any](columns *utils.ColumnSet, aliases map[string]string, key, period []string) (ImportConfig, error) {
+	columns, err := columns.WithAliases(aliases)
+	if err != nil {
+		return ImportConfig{}, err
+	}
+	if len(key) == 0 || len(period) == 0 {
+		return ImportConfig{}, fmt.Errorf("natural key and period fields must not be empty")
+	}
+
+	var zero T
+	doc := toDocument(&zero)
+	for _, field := range append(append([]string{}, key...), period...) {
+		if _, ok := doc[field]; !ok || field == "_id" {
+			return ImportConfig{}, fmt.Errorf("unknown field %q", field)
+		}
+	}
+
+	return ImportConfig{Columns: columns, Key: key, Period: period}, nil
+}
+
+// importBatchSize adalah jumlah baris yang diproses dan disimpan sekaligus.
+const importBatchSize = 500
+
+// importCheckReportRows adalah jarak (dalam baris) pengiriman progress selama validasi,
+// supaya job dengan file besar tetap memberi heartbeat sebelum batch pertama disimpan.
+const importCheckReportRows = 5000
+
+// ImportOptions adalah pilihan per request import.
+type ImportOptions struct {
+	DryRun bool
+	Mode   string
+	// FileName dipakai untuk membedakan TSV dari CSV; formatnya sendiri dikenali dari isi file.
+	FileName string
+	// Sheet memilih sheet workbook yang diimport; nilai kosong berarti sheet pertama.
+	Sheet utils.SheetSelection
+	// Numbers memaksa format angka file CSV/TSV; kosong berarti ditebak dari isi file.
+	Numbers utils.NumberFormat
+	// Progress dipanggil setiap importCheckReportRows baris selama validasi, setelah
+	// validasi, dan setelah setiap batch. Kalau mengembalikan error (mis.
+	// ErrImportCancelled), import berhenti sebelum batch berikutnya.
+	Progress func(ctx context.Context, progress models.ImportProgress) error
+	// Source dicatat di setiap data yang disimpan. Kalau diisi, versi data sebelum
+	// ditimpa atau dihapus juga disimpan supaya batch ini bisa di-rollback.
+	Source *models.ImportSource
+}
+
+func (o ImportOptions) report(ctx context.Context, progress models.ImportProgress) error {
+	if o.Progress == nil {
+		return nil
+	}
+	return o.Progress(ctx, progress)
+}
+
+// importSpec menghubungkan pipeline import generik dengan satu jenis data.
+type importSpec[T any] struct {
+	entity string
+	config ImportConfig
+	parse  func(*utils.ImportTable) (*utils.SheetReader[T], error)
+	// scopeColumn adalah nama kolom region di file, dipakai untuk pesan error scope.
+	scopeColumn string
+	scope       func(*T) (region, cabang string)
+	id          func(*T) string
+	// keep menyalin ID dan created_at dari data lama ke record yang akan menimpanya.
+	keep   func(record, existing *T)
+	find   func(context.Context, []bson.M) ([]T, error)
+	count  func(context.Context, bson.M) (int64, error)
+	insert func(context.Context, []T) error
+	update func(context.Context, []T) error
+	remove func(context.Context, bson.M) ([]T, error)
+	// source mengembalikan field asal import di record.
+	source  func(*T) *models.ImportSource
+	restore func(context.Context, []T) ([]T, error)
+	backups repository.ImportBackupRepository
+	audit   AuditService
+}
+
+// allows memeriksa apakah region/cabang item masuk scope user.
+func (s importSpec[T]) allows(ctx context.Context, item *T) bool {
+	region, cabang := s.scope(item)
+	return checkScope(ctx, region, cabang) == nil
+}
+
+type importCandidate[T any] struct {
+	// sheet adalah index laporan sheet di ImportReport.Sheets, -1 untuk CSV/TSV.
+	sheet  int
+	result models.ImportRowResult
+	record T
+	before *T
+	key    string
+	filter bson.M
+}
+
+func (c *importCandidate[T]) reject(reason string) {
+	c.result.Status = models.ImportRowError
+	c.result.Reason = reason
+}
+
+// runImport mem-parsing file, memvalidasi setiap baris termasuk scope user dan natural
+// key, lalu menyimpan baris yang valid per batch sesuai mode. Dengan DryRun tidak ada yang
+// ditulis; hasilnya hanya laporan per baris beserta perkiraan jumlah data yang berubah.
+//
+// File dibaca dua kali supaya memori tidak bergantung pada jumlah baris: tahap pertama
+// hanya memvalidasi dan mengumpulkan periode, tahap kedua membaca ulang dan menyimpan
+// per batch. Hasil validasi kedua tahap sama karena file dan urutannya sama.
+func runImport[T any](ctx context.Context, spec importSpec[T], file io.ReadSeeker, opts ImportOptions) (*models.ImportReport, error) {
+	if opts.Mode == "" {
+		opts.Mode = models.ImportModeUpsert
+	}
+
+	book, err := utils.OpenImportFile(file, opts.FileName, opts.Sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer book.Close()
+	book.Numbers = opts.Numbers
+
+	check := newImportScan(spec, opts.Sheet.All)
+	progress := models.ImportProgress{}
+	accepted, rows := 0, 0
+	err = check.run(ctx, book, func(c *importCandidate[T]) error {
+		switch c.result.Status {
+		case models.ImportRowAccepted:
+			accepted++
+			progress.Total++
+		case models.ImportRowError:
+			progress.Total++
+			progress.Processed++
+			progress.Failed++
+		}
+		if rows++; rows%importCheckReportRows == 0 {
+			return opts.report(ctx, progress)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// stop diisi kalau tidak ada lagi yang boleh ditulis; sisa file tetap dibaca untuk laporan.
+	var stop error
+	cancelled := false
+	if !opts.DryRun {
+		// Sama seperti input manual, data di luar scope menggagalkan seluruh import.
+		if check.outOfScope {
+			stop = ErrOutOfScope
+		} else if accepted == 0 {
+			stop = ErrNoValidImportRows
+		}
+	}
+
+	if stop == nil && opts.Mode == models.ImportModeReplace && len(check.periods) > 0 {
+		or := bson.A{}
+		for _, period := range check.periods {
+			or = append(or, period)
+		}
+		periodFilter := bson.M{"$or": or}
+
+		if opts.DryRun {
+			deleted, err := spec.count(ctx, periodFilter)
+			if err != nil {
+				return nil, err
+			}
+			progress.Deleted = int(deleted)
+		} else {
+			deleted, err := spec.remove(ctx, periodFilter)
+			if err != nil {
+				stop = fmt.Errorf("failed to delete period data: %v", err)
+			} else {
+				progress.Deleted = len(deleted)
+				stop = saveBackups(ctx, spec, opts, deleted)
+
+				entries := make([]AuditEntry, len(deleted))
+				for i := range deleted {
+					entries[i] = AuditEntry{EntityID: spec.id(&deleted[i]), Before: &deleted[i]}
+				}
+				spec.audit.RecordMany(ctx, spec.entity, models.AuditActionDelete, entries)
+			}
+		}
+	}
+
+	if stop == nil {
+		if err := opts.report(ctx, progress); err != nil {
+			stop, cancelled = err, true
+		}
+	}
+
+	report := models.NewImportReport(opts.DryRun, opts.Mode)
+	report.Format = book.Format
+
+	// Laporan baris diisi setelah batch-nya diproses supaya status yang berubah saat
+	// disimpan ikut tercatat. pending berisi semua baris sejak batch terakhir, urut file.
+	scan := newImportScan(spec, opts.Sheet.All)
+	var pending, batch []*importCandidate[T]
+	flush := func() {
+		if len(batch) > 0 {
+			if stop == nil {
+				stop = writeBatch(ctx, spec, batch, check.periods, opts, &progress)
+				if stop == nil {
+					if err := opts.report(ctx, progress); err != nil {
+						stop, cancelled = err, true
+					}
+				}
+			} else if cancelled {
+				cancelRemaining(batch)
+			}
+		}
+
+		for _, c := range pending {
+			if c.result.Status == models.ImportRowAccepted {
+				report.AddSample(c.record)
+			}
+			report.AddRow(c.result)
+			if c.sheet >= 0 {
+				scan.sheets[c.sheet].AddRow(c.result)
+			}
+		}
+		pending, batch = pending[:0], batch[:0]
+	}
+
+	err = scan.run(ctx, book, func(c *importCandidate[T]) error {
+		pending = append(pending, c)
+		if c.result.Status == models.ImportRowAccepted {
+			batch = append(batch, c)
+		}
+		if len(pending) >= importBatchSize {
+			flush()
+		}
+		return nil
+	})
+	flush()
+	if err != nil && stop == nil {
+		stop = err
+	}
+
+	report.Sheets = scan.sheets
+	report.IgnoredColumns = scan.ignored
+	report.Inserted = progress.Inserted
+	report.Updated = progress.Updated
+	report.Deleted = progress.Deleted
+	// Progress akhir tetap dikirim; permintaan batal di titik ini tidak mengubah apa pun.
+	_ = opts.report(ctx, progress)
+	return report, stop
+}
+
+// importScan membaca sheet yang dipilih dan memvalidasi setiap baris, termasuk scope user
+// dan duplikat natural key di dalam file. Yang disimpan di memori hanya hash natural key
+// dan periode, bukan isi baris.
+type importScan[T any] struct {
+	spec importSpec[T]
+	// all berarti semua sheet diimport; sheet yang tidak bisa diimport dilewati.
+	all        bool
+	seen       map[[16]byte]importSeenRow
+	periods    map[string]bson.M
+	outOfScope bool
+	sheets     []models.ImportSheetReport
+	ignored    []string
+}
+
+type importSeenRow struct {
+	sheet string
+	row   int
+}
+
+func newImportScan[T any](spec importSpec[T], all bool) *importScan[T] {
+	return &importScan[T]{
+		spec:    spec,
+		all:     all,
+		seen:    map[[16]byte]importSeenRow{},
+		periods: map[string]bson.M{},
+	}
+}
+
+// run memanggil visit untuk setiap baris di semua sheet yang dipilih, sesuai urutan file.
+func (s *importScan[T]) run(ctx context.Context, book *utils.ImportFile, visit func(*importCandidate[T]) error) error {
+	for i, name := range book.Sheets {
+		if err := s.readSheet(ctx, book, i, name, visit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *importScan[T]) readSheet(ctx context.Context, book *utils.ImportFile, i int, name string, visit func(*importCandidate[T]) error) error {
+	sheetIndex := -1
+	if name != "" {
+		sheetIndex = len(s.sheets)
+		s.sheets = append(s.sheets, models.ImportSheetReport{Sheet: name})
+	}
+
+	table, err := book.Table(i)
+	var reader *utils.SheetReader[T]
+	if err == nil {
+		defer table.Close()
+		reader, err = s.spec.parse(table)
+	}
+	if err != nil {
+		// Di mode semua sheet, sheet yang tidak bisa diimport (mis. sheet catatan)
+		// dilewati dan dicatat di laporannya; sheet lain tetap diproses.
+		if !s.all {
+			return fmt.Errorf("failed to parse import data: %w", err)
+		}
+		sheetReport := &s.sheets[sheetIndex]
+		sheetReport.Error, sheetReport.MissingColumns = sheetError(err)
+		return nil
+	}
+
+	if sheetIndex >= 0 {
+		s.sheets[sheetIndex].IgnoredColumns = reader.IgnoredColumns
+	}
+	for _, column := range reader.IgnoredColumns {
+		if !slices.Contains(s.ignored, column) {
+			s.ignored = append(s.ignored, column)
+		}
+	}
+
+	for {
+		row, ok, err := reader.Next()
+		if err != nil {
+			return fmt.Errorf("failed to read import data: %w", err)
+		}
+		if !ok {
+			return nil
+		}
+
+		c := &importCandidate[T]{sheet: sheetIndex, result: row.Result, record: row.Record}
+		if s.all {
+			c.result.Sheet = name
+		}
+		if c.result.Status == models.ImportRowAccepted {
+			s.validate(ctx, c, name)
+		}
+		if err := visit(c); err != nil {
+			return err
+		}
+	}
+}
+
+// validate memeriksa scope user dan duplikat natural key, lalu mencatat periode baris yang valid.
+func (s *importScan[T]) validate(ctx context.Context, c *importCandidate[T], sheet string) {
+	region, cabang := s.spec.scope(&c.record)
+	if err := checkScope(ctx, region, cabang); err != nil {
+		s.outOfScope = true
+		c.result.Status = models.ImportRowError
+		c.result.Errors = append(c.result.Errors, models.ImportFieldError{
+			Column: s.spec.scopeColumn,
+			Value:  region + "/" + cabang,
+			Reason: "di luar scope data user",
+		})
+		return
+	}
+
+	doc := toDocument(&c.record)
+	c.filter, c.key = naturalKey(doc, s.spec.config.Key)
+	hash := md5.Sum([]byte(c.key))
+	if first, ok := s.seen[hash]; ok {
+		if first.sheet != sheet {
+			c.reject(fmt.Sprintf("duplikat dengan baris %d di sheet %s", first.row, first.sheet))
+		} else {
+			c.reject(fmt.Sprintf("duplikat dengan baris %d", first.row))
+		}
+		return
+	}
+	s.seen[hash] = importSeenRow{sheet: sheet, row: c.result.Row}
+
+	period, periodKey := naturalKey(doc, s.spec.config.Period)
+	s.periods[periodKey] = period
+}
+
+// sheetError mengubah error parsing sheet menjadi pesan untuk laporan.
+func sheetError(err error) (string, []string) {
+	var missing *utils.MissingColumnsError
+	if errors.As(err, &missing) {
+		return "kolom wajib tidak ditemukan di header", missing.Columns
+	}
+	return err.Error(), nil
+}
+
+// listSheets membaca semua sheet di file dan memeriksa header setiap sheet, supaya user
+// bisa memilih sheet yang akan diimport. Tidak ada data yang disimpan.
+func listSheets[T any](spec importSpec[T], file io.ReadSeeker, fileName string) (*models.ImportWorkbook, error) {
+	book, err := utils.OpenImportFile(file, fileName, utils.SheetSelection{All: true})
+	if err != nil {
+		return nil, err
+	}
+	defer book.Close()
+
+	workbook := &models.ImportWorkbook{Format: book.Format, Sheets: make([]models.ImportSheet, 0, len(book.Sheets))}
+	for i, name := range book.Sheets {
+		sheet := models.ImportSheet{Index: i, Name: name}
+		if err := countSheet(spec, book, i, &sheet); err != nil {
+			sheet.Error, sheet.MissingColumns = sheetError(err)
+		}
+		workbook.Sheets = append(workbook.Sheets, sheet)
+	}
+	return workbook, nil
+}
+
+// countSheet memeriksa header satu sheet dan menghitung baris datanya.
+func countSheet[T any](spec importSpec[T], book *utils.ImportFile, i int, sheet *models.ImportSheet) error {
+	table, err := book.Table(i)
+	if err != nil {
+		return err
+	}
+	defer table.Close()
+
+	reader, err := spec.parse(table)
+	if err != nil {
+		return err
+	}
+	for {
+		_, ok, err := reader.Next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		sheet.Rows++
+	}
+
+	sheet.Importable = true
+	sheet.IgnoredColumns = reader.IgnoredColumns
+	return nil
+}
+
+// importTemplate membuat template import dari definisi kolom yang dipakai parser. fields
+// memetakan nama kolom ke field BSON yang nilainya (dalam scope user) dijadikan dropdown.
+func importTemplate(ctx context.Context, columns *utils.ColumnSet, distinct func(context.Context, string) ([]string, error), fields map[string]string) (*excelize.File, error) {
+	choices := map[string][]string{}
+	for column, field := range fields {
+		values, err := distinct(ctx, field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s values: %v", field, err)
+		}
+		choices[column] = values
+	}
+
+	file, err := utils.NewImportTemplate(columns, choices)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create template: %v", err)
+	}
+	return file, nil
+}
+
+// writeBatch menentukan aksi setiap baris di batch lalu menyimpannya (kecuali dry run).
+func writeBatch[T any](ctx context.Context, spec importSpec[T], batch []*importCandidate[T], periods map[string]bson.M, opts ImportOptions, progress *models.ImportProgress) error {
+	if err := resolveExisting(ctx, spec, batch, periods, opts.Mode); err != nil {
+		return err
+	}
+
+	var inserts, updates []T
+	var befores []*T
+	for _, c := range batch {
+		progress.Processed++
+		if opts.Source != nil {
+			*spec.source(&c.record) = *opts.Source
+		}
+		switch {
+		case c.result.Status != models.ImportRowAccepted:
+			progress.Failed++
+		case c.before == nil:
+			inserts = append(inserts, c.record)
+		default:
+			updates = append(updates, c.record)
+			befores = append(befores, c.before)
+		}
+	}
+
+	if opts.DryRun {
+		progress.Inserted += len(inserts)
+		progress.Updated += len(updates)
+		return nil
+	}
+
+	if len(befores) > 0 {
+		previous := make([]T, len(befores))
+		for i, before := range befores {
+			previous[i] = *before
+		}
+		if err := saveBackups(ctx, spec, opts, previous); err != nil {
+			return err
+		}
+	}
+
+	if len(inserts) > 0 {
+		if err := spec.insert(ctx, inserts); err != nil {
+			return fmt.Errorf("failed to insert data: %v", err)
+		}
+		progress.Inserted += len(inserts)
+
+		entries := make([]AuditEntry, len(inserts))
+		for i := range inserts {
+			entries[i] = AuditEntry{EntityID: spec.id(&inserts[i]), After: &inserts[i]}
+		}
+		spec.audit.RecordMany(ctx, spec.entity, models.AuditActionImport, entries)
+	}
+
+	if len(updates) > 0 {
+		if err := spec.update(ctx, updates); err != nil {
+			return fmt.Errorf("failed to update data: %v", err)
+		}
+		progress.Updated += len(updates)
+
+		entries := make([]AuditEntry, len(updates))
+		for i := range updates {
+			entries[i] = AuditEntry{EntityID: spec.id(&updates[i]), Before: befores[i], After: &updates[i]}
+		}
+		spec.audit.RecordMany(ctx, spec.entity, models.AuditActionUpdate, entries)
+	}
+
+	return nil
+}
+
+// saveBackups menyimpan versi data sebelum import untuk rollback batch.
+func saveBackups[T any](ctx context.Context, spec importSpec[T], opts ImportOptions, docs []T) error {
+	if opts.Source == nil || len(docs) == 0 {
+		return nil
+	}
+
+	backups := make([]interface{}, len(docs))
+	for i := range docs {
+		backups[i] = docs[i]
+	}
+	if err := spec.backups.SaveMany(ctx, spec.entity, opts.Source.ImportBatchID, backups); err != nil {
+		return fmt.Errorf("failed to back up existing data: %v", err)
+	}
+	return nil
+}
+
+// rollbackImport menghapus data yang masih tercatat dari satu batch import (dalam scope
+// user). Dengan restore, versi data sebelum batch itu ditimpa atau dihapus dikembalikan.
+// Data yang sudah diedit manual tidak lagi tercatat di batch, sehingga tidak dihapus dan
+// versi lamanya dilewati karena _id-nya masih dipakai.
+func rollbackImport[T any](ctx context.Context, spec importSpec[T], batchID string, restore bool) (*models.ImportRollback, error) {
+	result := &models.ImportRollback{Restore: restore}
+
+	deleted, err := spec.remove(ctx, bson.M{"import_batch_id": batchID})
+	if err != nil {
+		return result, fmt.Errorf("failed to delete import batch data: %v", err)
+	}
+	result.Deleted = len(deleted)
+
+	entries := make([]AuditEntry, len(deleted))
+	for i := range deleted {
+		entries[i] = AuditEntry{EntityID: spec.id(&deleted[i]), Before: &deleted[i]}
+	}
+	spec.audit.RecordMany(ctx, spec.entity, models.AuditActionRollback, entries)
+
+	if !restore {
+		return result, nil
+	}
+
+	raws, err := spec.backups.FindByBatch(ctx, spec.entity, batchID)
+	if err != nil {
+		return result, fmt.Errorf("failed to read import backups: %v", err)
+	}
+
+	var previous []T
+	for _, raw := range raws {
+		var doc T
+		if err := bson.Unmarshal(raw, &doc); err != nil {
+			return result, fmt.Errorf("failed to decode import backup: %v", err)
+		}
+		if !spec.allows(ctx, &doc) {
+			result.Skipped++
+			continue
+		}
+		previous = append(previous, doc)
+	}
+
+	restored, err := spec.restore(ctx, previous)
+	if err != nil {
+		return result, fmt.Errorf("failed to restore previous data: %v", err)
+	}
+	result.Restored = len(restored)
+	result.Skipped += len(previous) - len(restored)
+
+	entries = make([]AuditEntry, len(restored))
+	for i := range restored {
+		entries[i] = AuditEntry{EntityID: spec.id(&restored[i]), After: &restored[i]}
+	}
+	spec.audit.RecordMany(ctx, spec.entity, models.AuditActionRollback, entries)
+
+	return result, nil
+}
+
+// cancelRemaining menandai baris yang belum sempat diproses karena import dibatalkan.
+func cancelRemaining[T any](candidates []*importCandidate[T]) {
+	for _, c := range candidates {
+		c.result.Status = models.ImportRowSkipped
+		c.result.Action = ""
+		c.result.Reason = "tidak diproses karena import dibatalkan"
+	}
+}
+
+// resolveExisting menentukan apakah setiap baris valid akan ditambah atau menimpa data
+// yang natural key-nya sama, sesuai mode import.
+func resolveExisting[T any](ctx context.Context, spec importSpec[T], candidates []*importCandidate[T], periods map[string]bson.M, mode string) error {
+	var filters []bson.M
+	for _, c := range candidates {
+		if c.result.Status == models.ImportRowAccepted {
+			filters = append(filters, c.filter)
+		}
+	}
+	if len(filters) == 0 {
+		return nil
+	}
+
+	found, err := spec.find(ctx, filters)
+	if err != nil {
+		return fmt.Errorf("failed to look up existing data: %v", err)
+	}
+
+	existing := map[string]*T{}
+	for i := range found {
+		doc := toDocument(&found[i])
+		_, key := naturalKey(doc, spec.config.Key)
+
+		// Di mode replace, data dalam scope di periode yang sama dihapus lebih dulu,
+		// jadi barisnya dihitung sebagai data baru.
+		if mode == models.ImportModeReplace && spec.allows(ctx, &found[i]) {
+			if _, periodKey := naturalKey(doc, spec.config.Period); periods[periodKey] != nil {
+				continue
+			}
+		}
+		existing[key] = &found[i]
+	}
+
+	for _, c := range candidates {
+		if c.result.Status != models.ImportRowAccepted {
+			continue
+		}
+
+		before, ok := existing[c.key]
+		switch {
+		case !ok:
+			c.result.Action = models.ImportActionInsert
+		case mode == models.ImportModeInsert:
+			c.reject("data dengan kunci yang sama sudah ada")
+		case !spec.allows(ctx, before):
+			c.reject("data dengan kunci yang sama ada di luar scope data user")
+		default:
+			spec.keep(&c.record, before)
+			c.before = before
+			c.result.Action = models.ImportActionUpdate
+		}
+	}
+
+	return nil
+}
+
+// naturalKey mengambil field kunci dari dokumen sebagai filter query dan sebagai
+// string untuk pencocokan di memori.
+func naturalKey(doc bson.M, fields []string) (bson.M, string) {
+	filter := bson.M{}
+	parts := make([]string, len(fields))
+	for i, field := range fields {
+		filter[field] = doc[field]
+		parts[i] = fmt.Sprintf("%v", doc[field])
+	}
+	return filter, strings.Join(parts, "\x1f")
+}
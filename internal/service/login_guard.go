@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
+)
+
+var ErrLoginLocked = errors.New("too many failed login attempts")
+
+// LockoutError dikembalikan selama username atau IP masih terkunci.
+type LockoutError struct {
+	Until time.Time
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("%s, try again after %s", ErrLoginLocked, e.Until.Format(time.RFC3339))
+}
+
+func (e *LockoutError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// LoginGuardPolicy mengatur kapan username/IP dikunci dan berapa lama.
+// Setiap kegagalan di atas batas menggandakan durasi kunci sampai LockoutMax.
+type LoginGuardPolicy struct {
+	MaxFailures      int
+	MaxFailuresPerIP int
+	LockoutBase      time.Duration
+	LockoutMax       time.Duration
+	FailureWindow    time.Duration
+}
+
+type LoginGuard interface {
+	Check(ctx context.Context, username, ip string) error
+	Failure(ctx context.Context, username string, client models.ClientInfo, outcome string) error
+	Success(ctx context.Context, username string, client models.ClientInfo) error
+	Record(ctx context.Context, username string, client models.ClientInfo, outcome string)
+	Unlock(ctx context.Context, username string) error
+	GetAttempts(ctx context.Context, username string, page, perPage int) (*models.LoginAttemptListResponse, error)
+}
+
+type loginGuard struct {
+	repo   repository.LoginAttemptRepository
+	policy LoginGuardPolicy
+}
+
+func NewLoginGuard(repo repository.LoginAttemptRepository, policy LoginGuardPolicy) LoginGuard {
+	return &loginGuard{
+		repo:   repo,
+		policy: policy,
+	}
+}
+
+func (g *loginGuard) Check(ctx context.Context, username, ip string) error {
+	throttles, err := g.repo.FindThrottles(ctx, []string{userThrottleKey(username), ipThrottleKey(ip)})
+	if err != nil {
+		return err
+	}
+
+	var until time.Time
+	for _, t := range throttles {
+		if t.LockedUntil.After(until) {
+			until = t.LockedUntil
+		}
+	}
+
+	if until.After(time.Now()) {
+		return &LockoutError{Until: until}
+	}
+
+	return nil
+}
+
+func (g *loginGuard) Failure(ctx context.Context, username string, client models.ClientInfo, outcome string) error {
+	g.Record(ctx, username, client, outcome)
+
+	limits := map[string]int{
+		userThrottleKey(username): g.policy.MaxFailures,
+		ipThrottleKey(client.IP):  g.policy.MaxFailuresPerIP,
+	}
+
+	for key, limit := range limits {
+		throttle, err := g.repo.IncrementFailure(ctx, key, g.policy.FailureWindow)
+		if err != nil {
+			return err
+		}
+
+		if throttle.Failures >= limit {
+			until := time.Now().Add(g.lockoutDuration(throttle.Failures - limit))
+			if err := g.repo.SetLockedUntil(ctx, key, until); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *loginGuard) Success(ctx context.Context, username string, client models.ClientInfo) error {
+	g.Record(ctx, username, client, models.LoginOutcomeSuccess)
+
+	// Counter IP sengaja tidak di-reset, supaya satu akun valid tidak bisa dipakai
+	// untuk menghapus jejak tebakan password ke akun lain dari IP yang sama.
+	return g.repo.DeleteThrottle(ctx, userThrottleKey(username))
+}
+
+// Record mencatat percobaan login. Kegagalan menulis log tidak boleh menggagalkan login.
+func (g *loginGuard) Record(ctx context.Context, username string, client models.ClientInfo, outcome string) {
+	attempt := &models.LoginAttempt{
+		Username:  normalizeUsername(username),
+		IP:        client.IP,
+		UserAgent: client.UserAgent,
+		Outcome:   outcome,
+	}
+	if err := g.repo.Create(ctx, attempt); err != nil {
+		log.Printf("Warning: failed to record login attempt: %v", err)
+	}
+}
+
+func (g *loginGuard) Unlock(ctx context.Context, username string) error {
+	return g.repo.DeleteThrottle(ctx, userThrottleKey(username))
+}
+
+func (g *loginGuard) GetAttempts(ctx context.Context, username string, page, perPage int) (*models.LoginAttemptListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 10
+	}
+
+	data, total, err := g.repo.FindByUsername(ctx, normalizeUsername(username), page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage != 0 {
+		totalPages++
+	}
+
+	return &models.LoginAttemptListResponse{
+		Data:       data,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// lockoutDuration menghitung LockoutBase * 2^excess dengan batas LockoutMax.
+func (g *loginGuard) lockoutDuration(excess int) time.Duration {
+	d := float64(g.policy.LockoutBase) * math.Pow(2, float64(excess))
+	if d > float64(g.policy.LockoutMax) {
+		return g.policy.LockoutMax
+	}
+	return time.Duration(d)
+}
+
+func userThrottleKey(username string) string {
+	return "user:" + normalizeUsername(username)
+}
+
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
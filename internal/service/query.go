@@ -0,0 +1,270 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/pkg/utils"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var ErrInvalidQuery = errors.New("query tidak valid")
+
+// DataQuery adalah filter dan urutan data dari query string. List dan export memakai
+// query yang sama supaya isi file export sama dengan tampilan di dashboard.
+type DataQuery struct {
+	// Filters berisi nilai query parameter; parameter yang tidak dikenal diabaikan.
+	Filters map[string]string
+	// From dan To membatasi rentang tanggal (YYYY-MM-DD atau YYYY-MM), keduanya inklusif.
+	From string
+	To   string
+	// Sort berisi field dipisah koma, awalan "-" untuk urutan menurun, mis. "-tahun,bulan".
+	Sort string
+}
+
+// queryFilter memetakan satu query parameter ke field di database. Filter teks
+// mencocokkan sebagian isi tanpa memperhatikan huruf besar/kecil.
+type queryFilter struct {
+	param  string
+	field  string
+	number bool
+}
+
+type querySpec struct {
+	filters []queryFilter
+	columns *utils.ColumnSet
+	// dateField dipakai untuk from/to. Kosong berarti rentang dicocokkan ke tahun/bulan.
+	dateField   string
+	defaultSort bson.D
+}
+
+var (
+	colorisQuery = querySpec{
+		filters: []queryFilter{
+			{param: "region", field: "region"},
+			{param: "cabang", field: "cabang"},
+			{param: "bulan", field: "bulan"},
+		},
+		dateField:   "timestamp",
+		defaultSort: bson.D{{Key: "created_at", Value: -1}},
+	}
+
+	trainingQuery = querySpec{
+		filters: []queryFilter{
+			{param: "region", field: "region"},
+			{param: "cabang_area", field: "cabang_area"},
+			{param: "cabang", field: "cabang_area"},
+			{param: "bulan", field: "bulan"},
+		},
+		dateField:   "timestamp",
+		defaultSort: bson.D{{Key: "timestamp", Value: -1}},
+	}
+
+	selloutQuery = querySpec{
+		filters: []queryFilter{
+			{param: "tahun", field: "tahun", number: true},
+			{param: "bulan", field: "bulan", number: true},
+			{param: "reg", field: "reg"},
+			{param: "region", field: "reg"},
+			{param: "cabang", field: "cabang"},
+		},
+		defaultSort: bson.D{{Key: "tahun", Value: -1}, {Key: "bulan", Value: -1}},
+	}
+)
+
+// with mengembalikan salinan spec dengan definisi kolom yang dipakai untuk sort.
+func (spec querySpec) with(columns *utils.ColumnSet) querySpec {
+	spec.columns = columns
+	return spec
+}
+
+// build mengubah query menjadi filter dan urutan MongoDB.
+func (spec querySpec) build(q DataQuery) (bson.M, bson.D, error) {
+	filter := bson.M{}
+	for _, f := range spec.filters {
+		value := strings.TrimSpace(q.Filters[f.param])
+		if value == "" {
+			continue
+		}
+		if _, exists := filter[f.field]; exists {
+			continue
+		}
+		if f.number {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%w: %s harus berupa angka", ErrInvalidQuery, f.param)
+			}
+			filter[f.field] = n
+			continue
+		}
+		filter[f.field] = bson.M{"$regex": regexp.QuoteMeta(value), "$options": "i"}
+	}
+
+	from, err := parseQueryDate("from", q.From)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := parseQueryDate("to", q.To)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !from.IsZero() && !to.IsZero() && !to.end.After(from.start) {
+		return nil, nil, fmt.Errorf("%w: from harus sebelum to", ErrInvalidQuery)
+	}
+	if spec.dateField != "" {
+		dateRange := bson.M{}
+		if !from.IsZero() {
+			dateRange["$gte"] = from.start
+		}
+		if !to.IsZero() {
+			dateRange["$lt"] = to.end
+		}
+		if len(dateRange) > 0 {
+			filter[spec.dateField] = dateRange
+		}
+	} else {
+		var period bson.A
+		if !from.IsZero() {
+			y, m := from.start.Year(), int(from.start.Month())
+			period = append(period, bson.M{"$or": bson.A{
+				bson.M{"tahun": bson.M{"$gt": y}},
+				bson.M{"tahun": y, "bulan": bson.M{"$gte": m}},
+			}})
+		}
+		if !to.IsZero() {
+			last := to.end.Add(-time.Nanosecond)
+			y, m := last.Year(), int(last.Month())
+			period = append(period, bson.M{"$or": bson.A{
+				bson.M{"tahun": bson.M{"$lt": y}},
+				bson.M{"tahun": y, "bulan": bson.M{"$lte": m}},
+			}})
+		}
+		if len(period) > 0 {
+			filter["$and"] = period
+		}
+	}
+
+	sort, err := spec.sort(q.Sort)
+	if err != nil {
+		return nil, nil, err
+	}
+	return filter, sort, nil
+}
+
+// prepare membangun filter, urutan, dan kolom terpilih untuk export.
+func (spec querySpec) prepare(q DataQuery, columns []string) (bson.M, bson.D, *utils.ExportColumns, error) {
+	filter, sort, err := spec.build(q)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	selected, err := exportColumns(spec.columns, columns)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return filter, sort, selected, nil
+}
+
+// describe menjelaskan filter yang dipakai dalam bentuk singkat, mis. untuk judul laporan.
+func (spec querySpec) describe(q DataQuery) string {
+	var parts []string
+	seen := map[string]bool{}
+	for _, f := range spec.filters {
+		value := strings.TrimSpace(q.Filters[f.param])
+		if value == "" || seen[f.field] {
+			continue
+		}
+		seen[f.field] = true
+		parts = append(parts, f.param+" "+value)
+	}
+	from, to := strings.TrimSpace(q.From), strings.TrimSpace(q.To)
+	switch {
+	case from != "" && to != "":
+		parts = append(parts, fmt.Sprintf("periode %s s/d %s", from, to))
+	case from != "":
+		parts = append(parts, "mulai "+from)
+	case to != "":
+		parts = append(parts, "sampai "+to)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// sort membaca urutan "-tahun,bulan". Field boleh berupa nama field atau header kolom.
+// _id ditambahkan di akhir supaya urutan halaman stabil.
+func (spec querySpec) sort(value string) (bson.D, error) {
+	if strings.TrimSpace(value) == "" {
+		return spec.defaultSort, nil
+	}
+
+	var sort bson.D
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ",") {
+		part = strings.TrimSpace(part)
+		direction := 1
+		if strings.HasPrefix(part, "-") {
+			direction = -1
+			part = strings.TrimSpace(part[1:])
+		} else if strings.HasPrefix(part, "+") {
+			part = strings.TrimSpace(part[1:])
+		}
+		if part == "" {
+			continue
+		}
+
+		field := ""
+		if part == "created_at" || part == "updated_at" {
+			field = part
+		} else if col, ok := spec.columns.Lookup(part); ok {
+			field = col.Field
+		}
+		if field == "" {
+			return nil, fmt.Errorf("%w: tidak bisa mengurutkan berdasarkan %q", ErrInvalidQuery, part)
+		}
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		sort = append(sort, bson.E{Key: field, Value: direction})
+	}
+	if len(sort) == 0 {
+		return spec.defaultSort, nil
+	}
+	return append(sort, bson.E{Key: "_id", Value: 1}), nil
+}
+
+// queryDate adalah satu hari atau satu bulan: [start, end).
+type queryDate struct {
+	start time.Time
+	end   time.Time
+}
+
+func (d queryDate) IsZero() bool {
+	return d.start.IsZero()
+}
+
+func parseQueryDate(param, value string) (queryDate, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return queryDate{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return queryDate{start: t, end: t.AddDate(0, 0, 1)}, nil
+	}
+	if t, err := time.Parse("2006-01", value); err == nil {
+		return queryDate{start: t, end: t.AddDate(0, 1, 0)}, nil
+	}
+	return queryDate{}, fmt.Errorf("%w: %s harus berformat YYYY-MM-DD atau YYYY-MM", ErrInvalidQuery, param)
+}
+
+// exportColumns memilih kolom export; kolom yang tidak dikenal ditolak sebagai query tidak valid.
+func exportColumns(columns *utils.ColumnSet, names []string) (*utils.ExportColumns, error) {
+	selected, err := columns.Select(names)
+	var unknown *utils.UnknownColumnsError
+	if errors.As(err, &unknown) {
+		return nil, fmt.Errorf("%w: kolom tidak dikenal: %s", ErrInvalidQuery, strings.Join(unknown.Columns, ", "))
+	}
+	return selected, err
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/auth"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	reportTrendMonths  = 6
+	reportTopColorists = 10
+	reportMinimumTahun = 2000
+	reportMaximumTahun = 2100
+)
+
+type ReportService interface {
+	MonthlyReport(ctx context.Context, tahun, bulan int, cabang string) (*models.MonthlyReport, error)
+}
+
+type reportService struct {
+	sellout  repository.SelloutRepository
+	coloris  repository.ColorisRepository
+	training repository.TrainingRepository
+}
+
+func NewReportService(sellout repository.SelloutRepository, coloris repository.ColorisRepository, training repository.TrainingRepository) ReportService {
+	return &reportService{
+		sellout:  sellout,
+		coloris:  coloris,
+		training: training,
+	}
+}
+
+// MonthlyReport mengumpulkan isi laporan bulanan satu cabang: total sellout bulan itu,
+// tren beberapa bulan terakhir, top colorist, serta ringkasan nilai tes Coloris dan
+// Training per materi. Nama cabang dicocokkan utuh tanpa memperhatikan huruf besar/kecil.
+func (s *reportService) MonthlyReport(ctx context.Context, tahun, bulan int, cabang string) (*models.MonthlyReport, error) {
+	cabang = strings.TrimSpace(cabang)
+	if cabang == "" {
+		return nil, fmt.Errorf("%w: cabang wajib diisi", ErrInvalidQuery)
+	}
+	if tahun < reportMinimumTahun || tahun > reportMaximumTahun {
+		return nil, fmt.Errorf("%w: tahun tidak valid", ErrInvalidQuery)
+	}
+	if bulan < 1 || bulan > 12 {
+		return nil, fmt.Errorf("%w: bulan harus 1-12", ErrInvalidQuery)
+	}
+	if scope := auth.ScopeFromContext(ctx); scope.Cabang != "" && !strings.EqualFold(cabang, scope.Cabang) {
+		return nil, ErrOutOfScope
+	}
+
+	report := &models.MonthlyReport{
+		Tahun:       tahun,
+		Bulan:       bulan,
+		Cabang:      cabang,
+		GeneratedAt: time.Now(),
+	}
+	cabangMatch := bson.M{"$regex": "^" + regexp.QuoteMeta(cabang) + "$", "$options": "i"}
+	month := bson.M{"cabang": cabangMatch, "tahun": tahun, "bulan": bulan}
+
+	total, err := s.sellout.Summarize(ctx, month)
+	if err != nil {
+		return nil, err
+	}
+	if len(total) > 0 {
+		report.Sellout = total[0]
+	}
+	// Region dan penulisan nama cabang diambil dari data sellout bulan itu.
+	groups, err := s.sellout.Summarize(ctx, month, "reg", "cabang")
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) > 0 {
+		report.Region = groups[0].Reg
+		report.Cabang = groups[0].Cabang
+	}
+
+	if report.Trend, err = s.trend(ctx, cabangMatch, tahun, bulan); err != nil {
+		return nil, err
+	}
+	if report.TopColorists, err = s.sellout.TopColorists(ctx, month, reportTopColorists); err != nil {
+		return nil, err
+	}
+
+	start := time.Date(tahun, time.Month(bulan), 1, 0, 0, 0, 0, time.UTC)
+	period := bson.M{"$gte": start, "$lt": start.AddDate(0, 1, 0)}
+	if report.Coloris, err = s.coloris.SummarizeScores(ctx, bson.M{"cabang": cabangMatch, "timestamp": period}); err != nil {
+		return nil, err
+	}
+	if report.Training, err = s.training.SummarizeScores(ctx, bson.M{"cabang_area": cabangMatch, "timestamp": period}); err != nil {
+		return nil, err
+	}
+
+	if len(total) == 0 && len(report.Coloris) == 0 && len(report.Training) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+	return report, nil
+}
+
+// trend mengembalikan total sellout reportTrendMonths bulan terakhir sampai bulan laporan,
+// urut dari yang terlama. Bulan tanpa data tetap muncul dengan nilai nol.
+func (s *reportService) trend(ctx context.Context, cabangMatch bson.M, tahun, bulan int) ([]models.SelloutSummary, error) {
+	last := time.Date(tahun, time.Month(bulan), 1, 0, 0, 0, 0, time.UTC)
+	trend := make([]models.SelloutSummary, reportTrendMonths)
+	months := make(bson.A, reportTrendMonths)
+	for i := range trend {
+		t := last.AddDate(0, i-reportTrendMonths+1, 0)
+		trend[i] = models.SelloutSummary{Tahun: t.Year(), Bulan: int(t.Month())}
+		months[i] = bson.M{"tahun": t.Year(), "bulan": int(t.Month())}
+	}
+
+	summary, err := s.sellout.Summarize(ctx, bson.M{"cabang": cabangMatch, "$or": months}, "tahun", "bulan")
+	if err != nil {
+		return nil, err
+	}
+	for _, month := range summary {
+		for i := range trend {
+			if trend[i].Tahun == month.Tahun && trend[i].Bulan == month.Bulan {
+				trend[i] = month
+			}
+		}
+	}
+	return trend, nil
+}
@@ -0,0 +1,18 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/auth"
+)
+
+var ErrOutOfScope = errors.New("data berada di luar region/cabang Anda")
+
+// checkScope menolak data yang region/cabang-nya di luar scope user yang login.
+func checkScope(ctx context.Context, region, cabang string) error {
+	if !auth.ScopeFromContext(ctx).Allows(region, cabang) {
+		return ErrOutOfScope
+	}
+	return nil
+}
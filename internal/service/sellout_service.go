@@ -3,7 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
-	"mime/multipart"
+	"io"
 
 	"github.com/web-dashboard-made-by-renz/backend/internal/models"
 	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
@@ -18,18 +18,29 @@ type SelloutService interface {
 	GetAllSellout(ctx context.Context, page, perPage int) (*models.SelloutListResponse, error)
 	UpdateSellout(ctx context.Context, id string, req *models.SelloutCreateRequest) error
 	DeleteSellout(ctx context.Context, id string) error
-	ImportFromExcel(ctx context.Context, file *multipart.FileHeader) (int, error)
-	ExportToExcel(ctx context.Context) (*excelize.File, error)
-	GetSelloutWithFilters(ctx context.Context, filters map[string]string, page, perPage int) (*models.SelloutListResponse, error)
+	ImportFromExcel(ctx context.Context, file io.ReadSeeker, opts ImportOptions) (*models.ImportReport, error)
+	ImportSheets(ctx context.Context, file io.ReadSeeker, fileName string) (*models.ImportWorkbook, error)
+	ImportTemplate(ctx context.Context) (*excelize.File, error)
+	RollbackImport(ctx context.Context, batchID string, restore bool) (*models.ImportRollback, error)
+	ExportToExcel(ctx context.Context, query DataQuery, columns []string) (*excelize.File, error)
+	ExportRecords(ctx context.Context, query DataQuery, columns []string, open OpenRecordWriter) error
+	ExportReport(ctx context.Context, query DataQuery, columns []string) (*excelize.File, error)
+	GetSelloutWithFilters(ctx context.Context, query DataQuery, page, perPage int) (*models.SelloutListResponse, error)
 }
 
 type selloutService struct {
-	repo repository.SelloutRepository
+	repo         repository.SelloutRepository
+	audit        AuditService
+	backups      repository.ImportBackupRepository
+	importConfig ImportConfig
 }
 
-func NewSelloutService(repo repository.SelloutRepository) SelloutService {
+func NewSelloutService(repo repository.SelloutRepository, audit AuditService, backups repository.ImportBackupRepository, importConfig ImportConfig) SelloutService {
 	return &selloutService{
-		repo: repo,
+		repo:         repo,
+		audit:        audit,
+		backups:      backups,
+		importConfig: importConfig,
 	}
 }
 
@@ -55,7 +66,16 @@ func (s *selloutService) CreateSellout(ctx context.Context, req *models.SelloutC
 		TotalSellout:     req.TotalSellout,
 	}
 
-	return s.repo.Create(ctx, sellout)
+	if err := checkScope(ctx, sellout.Reg, sellout.Cabang); err != nil {
+		return err
+	}
+
+	if err := s.repo.Create(ctx, sellout); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, models.AuditEntitySellout, models.AuditActionCreate, AuditEntry{EntityID: sellout.ID.Hex(), After: sellout})
+	return nil
 }
 
 func (s *selloutService) GetSelloutById(ctx context.Context, id string) (*models.Sellout, error) {
@@ -111,58 +131,134 @@ func (s *selloutService) UpdateSellout(ctx context.Context, id string, req *mode
 		TotalSellout:     req.TotalSellout,
 	}
 
-	return s.repo.Update(ctx, id, sellout)
-}
-
-func (s *selloutService) DeleteSellout(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
-}
+	if err := checkScope(ctx, sellout.Reg, sellout.Cabang); err != nil {
+		return err
+	}
 
-func (s *selloutService) ImportFromExcel(ctx context.Context, fileHeader *multipart.FileHeader) (int, error) {
-	file, err := fileHeader.Open()
+	before, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return 0, fmt.Errorf("failed to open file: %v", err)
+		return err
 	}
-	defer file.Close()
 
-	excelFile, err := excelize.OpenReader(file)
-	if err != nil {
-		return 0, fmt.Errorf("failed to read Excel file: %v", err)
+	if err := s.repo.Update(ctx, id, sellout); err != nil {
+		return err
 	}
-	defer excelFile.Close()
 
-	selloutData, err := utils.ParseExcelToSellout(excelFile)
+	// After dibaca ulang supaya audit mencatat dokumen yang benar-benar tersimpan,
+	// termasuk field yang tidak berasal dari request (created_at, asal import).
+	var after interface{} = sellout
+	if saved, err := s.repo.FindByID(ctx, id); err == nil {
+		after = saved
+	} else {
+		sellout.ID = before.ID
+		sellout.CreatedAt = before.CreatedAt
+	}
+	s.audit.Record(ctx, models.AuditEntitySellout, models.AuditActionUpdate, AuditEntry{EntityID: id, Before: before, After: after})
+	return nil
+}
+
+func (s *selloutService) DeleteSellout(ctx context.Context, id string) error {
+	before, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return 0, fmt.Errorf("failed to parse Excel data: %v", err)
+		return err
 	}
 
-	if len(selloutData) == 0 {
-		return 0, fmt.Errorf("no valid data found in Excel file")
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
 	}
 
-	err = s.repo.InsertMany(ctx, selloutData)
-	if err != nil {
-		return 0, fmt.Errorf("failed to insert data: %v", err)
+	s.audit.Record(ctx, models.AuditEntitySellout, models.AuditActionDelete, AuditEntry{EntityID: id, Before: before})
+	return nil
+}
+
+func (s *selloutService) ImportFromExcel(ctx context.Context, file io.ReadSeeker, opts ImportOptions) (*models.ImportReport, error) {
+	return runImport(ctx, s.importSpec(), file, opts)
+}
+
+func (s *selloutService) ImportSheets(ctx context.Context, file io.ReadSeeker, fileName string) (*models.ImportWorkbook, error) {
+	return listSheets(s.importSpec(), file, fileName)
+}
+
+func (s *selloutService) ImportTemplate(ctx context.Context) (*excelize.File, error) {
+	return importTemplate(ctx, s.importConfig.Columns, s.repo.Distinct, map[string]string{"Reg": "reg", "Cabang": "cabang"})
+}
+
+func (s *selloutService) RollbackImport(ctx context.Context, batchID string, restore bool) (*models.ImportRollback, error) {
+	return rollbackImport(ctx, s.importSpec(), batchID, restore)
+}
+
+func (s *selloutService) importSpec() importSpec[models.Sellout] {
+	return importSpec[models.Sellout]{
+		entity: models.AuditEntitySellout,
+		config: s.importConfig,
+		parse: func(table *utils.ImportTable) (*utils.SheetReader[models.Sellout], error) {
+			return utils.ParseSellout(table, s.importConfig.Columns)
+		},
+		scopeColumn: "Reg",
+		scope:       func(d *models.Sellout) (string, string) { return d.Reg, d.Cabang },
+		id:          func(d *models.Sellout) string { return d.ID.Hex() },
+		keep: func(d, existing *models.Sellout) {
+			d.ID = existing.ID
+			d.CreatedAt = existing.CreatedAt
+		},
+		find: s.repo.FindByKeys,
+		count: func(ctx context.Context, filter bson.M) (int64, error) {
+			_, total, err := s.repo.FindWithFilters(ctx, filter, nil, 1, 1)
+			return total, err
+		},
+		insert:  s.repo.InsertMany,
+		update:  s.repo.ReplaceMany,
+		remove:  s.repo.DeleteMatching,
+		source:  func(d *models.Sellout) *models.ImportSource { return &d.ImportSource },
+		restore: s.repo.Restore,
+		backups: s.backups,
+		audit:   s.audit,
 	}
+}
 
-	return len(selloutData), nil
+// ExportToExcel mengekspor semua data yang cocok dengan query, dengan urutan yang sama
+// seperti list, memakai kolom terpilih (semua kolom kalau kosong).
+func (s *selloutService) ExportToExcel(ctx context.Context, query DataQuery, columns []string) (*excelize.File, error) {
+	return exportExcel(ctx, selloutQuery.with(s.importConfig.Columns), query, columns, "Sellout Data", s.repo.ForEach, utils.SelloutRow)
 }
 
-func (s *selloutService) ExportToExcel(ctx context.Context) (*excelize.File, error) {
-	data, _, err := s.repo.FindAll(ctx, 1, 999999)
+// ExportRecords mengekspor data yang cocok dengan query ke CSV, NDJSON, atau JSON.
+func (s *selloutService) ExportRecords(ctx context.Context, query DataQuery, columns []string, open OpenRecordWriter) error {
+	return exportRecords(ctx, selloutQuery.with(s.importConfig.Columns), query, columns, open, s.repo.ForEach, utils.SelloutRow)
+}
+
+// ExportReport membuat laporan sellout: sheet Ringkasan berisi total per region dan cabang,
+// pencapaian terhadap target, dan grafik; sheet Data berisi data yang cocok dengan query.
+func (s *selloutService) ExportReport(ctx context.Context, query DataQuery, columns []string) (*excelize.File, error) {
+	spec := selloutQuery.with(s.importConfig.Columns)
+	filter, sort, selected, err := spec.prepare(query, columns)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch data: %v", err)
+		return nil, err
 	}
 
-	excelFile, err := utils.ExportSelloutToExcel(data)
+	report := utils.SelloutReport{Description: spec.describe(query)}
+	total, err := s.repo.Summarize(ctx, filter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Excel file: %v", err)
+		return nil, fmt.Errorf("failed to summarize data: %v", err)
+	}
+	if len(total) > 0 {
+		report.Total = total[0]
+	}
+	if report.Regions, err = s.repo.Summarize(ctx, filter, "reg"); err != nil {
+		return nil, fmt.Errorf("failed to summarize data: %v", err)
+	}
+	if report.Cabangs, err = s.repo.Summarize(ctx, filter, "reg", "cabang"); err != nil {
+		return nil, fmt.Errorf("failed to summarize data: %v", err)
 	}
 
-	return excelFile, nil
+	writer, err := utils.NewSelloutReport(report, selected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Excel file: %v", err)
+	}
+	return fillExcel(ctx, writer, filter, sort, s.repo.ForEach, utils.SelloutRow)
 }
 
-func (s *selloutService) GetSelloutWithFilters(ctx context.Context, filters map[string]string, page, perPage int) (*models.SelloutListResponse, error) {
+func (s *selloutService) GetSelloutWithFilters(ctx context.Context, query DataQuery, page, perPage int) (*models.SelloutListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -170,20 +266,12 @@ func (s *selloutService) GetSelloutWithFilters(ctx context.Context, filters map[
 		perPage = 10
 	}
 
-	bsonFilters := bson.M{}
-	for key, value := range filters {
-		if value != "" {
-			// For tahun and bulan, convert to int
-			if key == "tahun" || key == "bulan" {
-				// Try to parse as int for exact match
-				bsonFilters[key] = value
-			} else {
-				bsonFilters[key] = bson.M{"$regex": value, "$options": "i"}
-			}
-		}
+	filter, sort, err := selloutQuery.with(s.importConfig.Columns).build(query)
+	if err != nil {
+		return nil, err
 	}
 
-	data, total, err := s.repo.FindWithFilters(ctx, bsonFilters, page, perPage)
+	data, total, err := s.repo.FindWithFilters(ctx, filter, sort, page, perPage)
 	if err != nil {
 		return nil, err
 	}
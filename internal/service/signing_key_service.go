@@ -0,0 +1,401 @@
+package service
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
+	"github.com/web-dashboard-made-by-renz/backend/pkg/utils"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	SigningAlgRS256 = "RS256"
+	SigningAlgEdDSA = "EdDSA"
+
+	// keyCacheTTL menentukan seberapa sering key dibaca ulang dari MongoDB, supaya
+	// rotasi dari instance lain ikut terlihat tanpa query di setiap request.
+	keyCacheTTL = 5 * time.Minute
+	// keyReloadCooldown membatasi reload paksa saat token memakai kid yang belum dikenal.
+	keyReloadCooldown = 10 * time.Second
+)
+
+var (
+	ErrUnknownSigningKey = errors.New("unknown signing key")
+	ErrSigningKeyRotated = errors.New("signing key was rotated concurrently, try again")
+)
+
+// SigningKeyConfig mengatur algoritma dan jadwal rotasi key JWT.
+type SigningKeyConfig struct {
+	Algorithm        string
+	RotationInterval time.Duration
+	GracePeriod      time.Duration
+	EncryptionSecret string
+}
+
+type SigningKeyService interface {
+	EnsureActiveKey(ctx context.Context) error
+	Sign(ctx context.Context, claims jwt.Claims) (string, error)
+	Keyfunc(token *jwt.Token) (interface{}, error)
+	JWKS(ctx context.Context) (*models.JWKS, error)
+	Rotate(ctx context.Context) (*models.SigningKey, error)
+}
+
+type loadedKey struct {
+	kid       string
+	alg       string
+	private   crypto.Signer
+	public    crypto.PublicKey
+	active    bool
+	createdAt time.Time
+}
+
+type signingKeyService struct {
+	repo   repository.SigningKeyRepository
+	config SigningKeyConfig
+
+	mu       sync.RWMutex
+	keys     map[string]*loadedKey
+	active   *loadedKey
+	loadedAt time.Time
+}
+
+func NewSigningKeyService(repo repository.SigningKeyRepository, config SigningKeyConfig) SigningKeyService {
+	return &signingKeyService{
+		repo:   repo,
+		config: config,
+		keys:   map[string]*loadedKey{},
+	}
+}
+
+func (s *signingKeyService) EnsureActiveKey(ctx context.Context) error {
+	_, err := s.signingKey(ctx)
+	return err
+}
+
+func (s *signingKeyService) Sign(ctx context.Context, claims jwt.Claims) (string, error) {
+	key, err := s.signingKey(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(signingMethod(key.alg), claims)
+	token.Header["kid"] = key.kid
+
+	return token.SignedString(key.private)
+}
+
+// Keyfunc memilih public key berdasarkan header kid untuk dipakai jwt.Parse.
+func (s *signingKeyService) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, ErrUnknownSigningKey
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key, err := s.verificationKey(ctx, kid)
+	if err != nil {
+		return nil, err
+	}
+
+	if token.Method.Alg() != key.alg {
+		return nil, fmt.Errorf("unexpected signing method %s for key %s", token.Method.Alg(), kid)
+	}
+
+	return key.public, nil
+}
+
+func (s *signingKeyService) JWKS(ctx context.Context) (*models.JWKS, error) {
+	if err := s.refreshIfStale(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]*loadedKey, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].createdAt.After(keys[j].createdAt)
+	})
+
+	jwks := &models.JWKS{Keys: []models.JWK{}}
+	for _, key := range keys {
+		jwks.Keys = append(jwks.Keys, toJWK(key))
+	}
+
+	return jwks, nil
+}
+
+// Rotate membuat key aktif baru. Key aktif sebelumnya tetap bisa memverifikasi token
+// selama GracePeriod, jadi user yang sedang login tidak ikut ter-logout.
+func (s *signingKeyService) Rotate(ctx context.Context) (*models.SigningKey, error) {
+	if err := s.reload(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	current := s.active
+	s.mu.RUnlock()
+
+	return s.rotate(ctx, current)
+}
+
+// rotate mengganti key aktif current (nil kalau belum ada) dengan key baru. Penggantian
+// hanya berhasil kalau current masih key aktif di MongoDB; kalau instance lain lebih dulu
+// merotasi, hasilnya ErrSigningKeyRotated dan key aktif dibaca ulang dari MongoDB.
+func (s *signingKeyService) rotate(ctx context.Context, current *loadedKey) (*models.SigningKey, error) {
+	key, err := s.generate()
+	if err != nil {
+		return nil, err
+	}
+
+	currentKid := ""
+	if current != nil {
+		currentKid = current.kid
+	}
+	err = s.repo.ReplaceActive(ctx, currentKid, key, time.Now().Add(s.config.GracePeriod))
+	if errors.Is(err, mongo.ErrNoDocuments) || mongo.IsDuplicateKeyError(err) {
+		if err := s.reload(ctx); err != nil {
+			return nil, err
+		}
+		return nil, ErrSigningKeyRotated
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("JWT signing key rotated, new kid %s", key.Kid)
+
+	if err := s.reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (s *signingKeyService) signingKey(ctx context.Context) (*loadedKey, error) {
+	if err := s.refreshIfStale(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	active := s.active
+	s.mu.RUnlock()
+
+	if active == nil || time.Since(active.createdAt) > s.config.RotationInterval {
+		// Kalau instance lain sudah merotasi, key aktifnya sudah dibaca ulang dan langsung dipakai.
+		if _, err := s.rotate(ctx, active); err != nil && !errors.Is(err, ErrSigningKeyRotated) {
+			return nil, err
+		}
+
+		s.mu.RLock()
+		active = s.active
+		s.mu.RUnlock()
+	}
+
+	if active == nil {
+		return nil, errors.New("no active signing key")
+	}
+
+	return active, nil
+}
+
+func (s *signingKeyService) verificationKey(ctx context.Context, kid string) (*loadedKey, error) {
+	if err := s.refreshIfStale(ctx); err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	key, ok := s.keys[kid]
+	loadedAt := s.loadedAt
+	s.mu.RUnlock()
+
+	// Key baru dari instance lain mungkin belum ada di cache.
+	if !ok && time.Since(loadedAt) > keyReloadCooldown {
+		if err := s.reload(ctx); err != nil {
+			return nil, err
+		}
+
+		s.mu.RLock()
+		key, ok = s.keys[kid]
+		s.mu.RUnlock()
+	}
+
+	if !ok {
+		return nil, ErrUnknownSigningKey
+	}
+
+	return key, nil
+}
+
+func (s *signingKeyService) refreshIfStale(ctx context.Context) error {
+	s.mu.RLock()
+	stale := time.Since(s.loadedAt) > keyCacheTTL
+	s.mu.RUnlock()
+
+	if !stale {
+		return nil
+	}
+	return s.reload(ctx)
+}
+
+func (s *signingKeyService) reload(ctx context.Context) error {
+	stored, err := s.repo.FindUsable(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]*loadedKey{}
+	var active *loadedKey
+	for _, sk := range stored {
+		key, err := s.parse(sk)
+		if err != nil {
+			log.Printf("Warning: skipping signing key %s: %v", sk.Kid, err)
+			continue
+		}
+
+		keys[key.kid] = key
+		// Hasil query terurut dari yang terbaru, jadi key aktif pertama dipakai untuk signing.
+		if key.active && active == nil {
+			active = key
+		}
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.active = active
+	s.loadedAt = time.Now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *signingKeyService) generate() (*models.SigningKey, error) {
+	var private crypto.Signer
+	var err error
+
+	switch s.config.Algorithm {
+	case SigningAlgEdDSA:
+		_, private, err = ed25519.GenerateKey(rand.Reader)
+	case SigningAlgRS256:
+		private, err = rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm: %s", s.config.Algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	privatePEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}))
+	if s.config.EncryptionSecret != "" {
+		privatePEM, err = utils.EncryptString(s.config.EncryptionSecret, privatePEM)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	kid, err := utils.RandomToken(12)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SigningKey{
+		Kid:        kid,
+		Algorithm:  s.config.Algorithm,
+		PrivateKey: privatePEM,
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+		Status:     models.SigningKeyStatusActive,
+	}, nil
+}
+
+func (s *signingKeyService) parse(sk models.SigningKey) (*loadedKey, error) {
+	privatePEM := sk.PrivateKey
+	if s.config.EncryptionSecret != "" {
+		decrypted, err := utils.DecryptString(s.config.EncryptionSecret, privatePEM)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt private key: %w", err)
+		}
+		privatePEM = decrypted
+	}
+
+	block, _ := pem.Decode([]byte(privatePEM))
+	if block == nil {
+		return nil, errors.New("invalid private key PEM")
+	}
+
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	private, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, errors.New("private key is not a signer")
+	}
+
+	return &loadedKey{
+		kid:       sk.Kid,
+		alg:       sk.Algorithm,
+		private:   private,
+		public:    private.Public(),
+		active:    sk.Status == models.SigningKeyStatusActive,
+		createdAt: sk.CreatedAt,
+	}, nil
+}
+
+func signingMethod(alg string) jwt.SigningMethod {
+	if alg == SigningAlgEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+func toJWK(key *loadedKey) models.JWK {
+	jwk := models.JWK{
+		Kid: key.kid,
+		Alg: key.alg,
+		Use: "sig",
+	}
+
+	switch pub := key.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+	}
+
+	return jwk
+}
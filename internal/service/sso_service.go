@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
+	"github.com/web-dashboard-made-by-renz/backend/pkg/utils"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const defaultSSOStateTTL = 10 * time.Minute
+
+var (
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	ErrInvalidSSOState = errors.New("invalid or expired SSO state")
+	ErrSSOLoginFailed  = errors.New("SSO login failed")
+	ErrSSOUserNotFound = errors.New("no local user is linked to this SSO account")
+	ErrSSOUserConflict = errors.New("username already belongs to another account")
+)
+
+// SSOMapping mengatur cara identitas dari provider dipetakan ke user lokal.
+type SSOMapping struct {
+	// RoleMapping memetakan group/role dari provider ke role lokal. Kalau diisi,
+	// role user disinkronkan setiap login; kalau kosong, role dikelola admin.
+	RoleMapping map[string]string
+	DefaultRole string
+	// SyncScope menimpa scope region/cabang user dengan claim dari provider setiap login.
+	SyncScope       bool
+	AutoCreateUsers bool
+	// LinkByUsername menghubungkan akun SSO ke user lokal yang username-nya sama
+	// dan belum terhubung ke akun SSO lain.
+	LinkByUsername bool
+	StateTTL       time.Duration
+}
+
+type SSOService interface {
+	Providers() []string
+	Start(ctx context.Context, provider string) (*models.SSOStartResponse, error)
+	Callback(ctx context.Context, provider, code, state string, client models.ClientInfo) (*models.LoginResponse, error)
+}
+
+type ssoService struct {
+	providers   map[string]IdentityProvider
+	mapping     SSOMapping
+	stateRepo   repository.SSOStateRepository
+	userRepo    repository.UserRepository
+	authService AuthService
+	loginGuard  LoginGuard
+}
+
+func NewSSOService(providers []IdentityProvider, mapping SSOMapping, stateRepo repository.SSOStateRepository, userRepo repository.UserRepository, authService AuthService, loginGuard LoginGuard) SSOService {
+	if mapping.DefaultRole == "" {
+		mapping.DefaultRole = models.RoleViewer
+	}
+	if mapping.StateTTL <= 0 {
+		mapping.StateTTL = defaultSSOStateTTL
+	}
+	for group, role := range mapping.RoleMapping {
+		if !models.IsValidRole(role) {
+			log.Printf("Warning: SSO role mapping %q -> %q ignored, unknown role", group, role)
+		}
+	}
+
+	byName := map[string]IdentityProvider{}
+	for _, p := range providers {
+		byName[p.Name()] = p
+	}
+
+	return &ssoService{
+		providers:   byName,
+		mapping:     mapping,
+		stateRepo:   stateRepo,
+		userRepo:    userRepo,
+		authService: authService,
+		loginGuard:  loginGuard,
+	}
+}
+
+func (s *ssoService) Providers() []string {
+	names := []string{}
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Start menyiapkan state, nonce dan PKCE verifier lalu mengembalikan URL login provider.
+func (s *ssoService) Start(ctx context.Context, provider string) (*models.SSOStartResponse, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	state, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := utils.RandomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := utils.RandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := p.AuthCodeURL(ctx, state, nonce, codeChallenge(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrSSOLoginFailed, err)
+	}
+
+	err = s.stateRepo.Create(ctx, &models.SSOState{
+		State:        state,
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ExpiresAt:    time.Now().Add(s.mapping.StateTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SSOStartResponse{
+		AuthURL: authURL,
+		State:   state,
+	}, nil
+}
+
+// Callback menukar authorization code, memetakan identitas ke user lokal,
+// lalu menerbitkan access dan refresh token seperti login biasa.
+func (s *ssoService) Callback(ctx context.Context, provider, code, state string, client models.ClientInfo) (*models.LoginResponse, error) {
+	p, ok := s.providers[provider]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	saved, err := s.stateRepo.Consume(ctx, state)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrInvalidSSOState
+		}
+		return nil, err
+	}
+	if saved.Provider != provider {
+		return nil, ErrInvalidSSOState
+	}
+
+	identity, err := p.Exchange(ctx, code, saved.CodeVerifier, saved.Nonce)
+	if err != nil {
+		log.Printf("Warning: SSO login via %s failed: %v", provider, err)
+		return nil, fmt.Errorf("%w: %v", ErrSSOLoginFailed, err)
+	}
+
+	user, err := s.resolveUser(ctx, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status != models.UserStatusActive {
+		s.loginGuard.Record(ctx, user.Username, client, models.LoginOutcomeAccountDisabled)
+		return nil, ErrAccountDisabled
+	}
+
+	if err := s.syncProfile(ctx, user, identity); err != nil {
+		return nil, err
+	}
+
+	s.loginGuard.Record(ctx, user.Username, client, models.LoginOutcomeSSOSuccess)
+
+	return s.authService.IssueSession(ctx, user, client)
+}
+
+func (s *ssoService) resolveUser(ctx context.Context, identity *models.ExternalIdentity) (*models.User, error) {
+	user, err := s.userRepo.FindByExternalID(ctx, identity.Provider, identity.Subject)
+	if err == nil {
+		return user, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	user, err = s.userRepo.FindByUsername(ctx, identity.Username)
+	switch {
+	case err == nil:
+		if user.Subject != "" || !s.mapping.LinkByUsername {
+			return nil, ErrSSOUserConflict
+		}
+		if err := s.userRepo.LinkExternalIdentity(ctx, user.ID.Hex(), identity.Provider, identity.Subject); err != nil {
+			return nil, err
+		}
+		user.Provider = identity.Provider
+		user.Subject = identity.Subject
+		log.Printf("User %q linked to %s account %s", user.Username, identity.Provider, identity.Subject)
+		return user, nil
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		return nil, err
+	}
+
+	if !s.mapping.AutoCreateUsers {
+		return nil, ErrSSOUserNotFound
+	}
+	// Scope kosong berarti akses ke semua region dan cabang, jadi user tanpa claim scope
+	// tidak dibuat otomatis; user seperti itu harus dibuat admin.
+	scope := normalizeScope(models.DataScope{Region: identity.Region, Cabang: identity.Cabang})
+	if scope.IsEmpty() {
+		log.Printf("Warning: %s account %s has no region/cabang claim, user not created", identity.Provider, identity.Subject)
+		return nil, ErrSSOUserNotFound
+	}
+
+	user = &models.User{
+		Username:    identity.Username,
+		DisplayName: identity.DisplayName,
+		Role:        s.mapRole(identity.Groups, s.mapping.DefaultRole),
+		Status:      models.UserStatusActive,
+		Scope:       scope,
+		Provider:    identity.Provider,
+		Subject:     identity.Subject,
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrSSOUserConflict
+		}
+		return nil, err
+	}
+
+	log.Printf("User %q created from %s login", user.Username, identity.Provider)
+	return user, nil
+}
+
+// syncProfile menyamakan nama, role dan scope user dengan data terbaru dari provider,
+// sesuai SSOMapping. Perubahan langsung berlaku di token yang akan diterbitkan.
+func (s *ssoService) syncProfile(ctx context.Context, user *models.User, identity *models.ExternalIdentity) error {
+	displayName := user.DisplayName
+	if identity.DisplayName != "" {
+		displayName = identity.DisplayName
+	}
+
+	role := user.Role
+	if len(s.mapping.RoleMapping) > 0 {
+		role = s.mapRole(identity.Groups, s.mapping.DefaultRole)
+	}
+
+	// Kalau provider tidak mengirim claim region/cabang, scope yang tersimpan dipertahankan
+	// supaya pembatasan yang diatur admin tidak terhapus.
+	scope := user.Scope
+	if claimed := normalizeScope(models.DataScope{Region: identity.Region, Cabang: identity.Cabang}); s.mapping.SyncScope && !claimed.IsEmpty() {
+		scope = claimed
+	}
+
+	if displayName == user.DisplayName && role == user.Role && scope == user.Scope {
+		return nil
+	}
+
+	if err := s.userRepo.UpdateProfile(ctx, user.ID.Hex(), displayName, role, scope); err != nil {
+		return err
+	}
+
+	user.DisplayName = displayName
+	user.Role = role
+	user.Scope = scope
+	return nil
+}
+
+// mapRole memilih role dengan permission terbanyak dari semua group yang cocok.
+func (s *ssoService) mapRole(groups []string, fallback string) string {
+	role := ""
+	for _, group := range groups {
+		mapped, ok := s.mapping.RoleMapping[strings.TrimSpace(group)]
+		if !ok || !models.IsValidRole(mapped) {
+			continue
+		}
+		if role == "" || len(models.RolePermissions[mapped]) > len(models.RolePermissions[role]) {
+			role = mapped
+		}
+	}
+
+	if role == "" {
+		return fallback
+	}
+	return role
+}
+
+// codeChallenge menghitung PKCE code_challenge metode S256 (RFC 7636).
+func codeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -3,7 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
-	"mime/multipart"
+	"io"
 
 	"github.com/web-dashboard-made-by-renz/backend/internal/models"
 	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
@@ -18,18 +18,28 @@ type TrainingService interface {
 	GetAllTraining(ctx context.Context, page, perPage int) (*models.TrainingListResponse, error)
 	UpdateTraining(ctx context.Context, id string, req *models.TrainingCreateRequest) error
 	DeleteTraining(ctx context.Context, id string) error
-	ImportFromExcel(ctx context.Context, file *multipart.FileHeader) (int, error)
-	ExportToExcel(ctx context.Context) (*excelize.File, error)
-	GetTrainingWithFilters(ctx context.Context, filters map[string]string, page, perPage int) (*models.TrainingListResponse, error)
+	ImportFromExcel(ctx context.Context, file io.ReadSeeker, opts ImportOptions) (*models.ImportReport, error)
+	ImportSheets(ctx context.Context, file io.ReadSeeker, fileName string) (*models.ImportWorkbook, error)
+	ImportTemplate(ctx context.Context) (*excelize.File, error)
+	RollbackImport(ctx context.Context, batchID string, restore bool) (*models.ImportRollback, error)
+	ExportToExcel(ctx context.Context, query DataQuery, columns []string) (*excelize.File, error)
+	ExportRecords(ctx context.Context, query DataQuery, columns []string, open OpenRecordWriter) error
+	GetTrainingWithFilters(ctx context.Context, query DataQuery, page, perPage int) (*models.TrainingListResponse, error)
 }
 
 type trainingService struct {
-	repo repository.TrainingRepository
+	repo         repository.TrainingRepository
+	audit        AuditService
+	backups      repository.ImportBackupRepository
+	importConfig ImportConfig
 }
 
-func NewTrainingService(repo repository.TrainingRepository) TrainingService {
+func NewTrainingService(repo repository.TrainingRepository, audit AuditService, backups repository.ImportBackupRepository, importConfig ImportConfig) TrainingService {
 	return &trainingService{
-		repo: repo,
+		repo:         repo,
+		audit:        audit,
+		backups:      backups,
+		importConfig: importConfig,
 	}
 }
 
@@ -53,7 +63,16 @@ func (s *trainingService) CreateTraining(ctx context.Context, req *models.Traini
 		Total:                req.Total,
 	}
 
-	return s.repo.Create(ctx, training)
+	if err := checkScope(ctx, training.Region, training.CabangArea); err != nil {
+		return err
+	}
+
+	if err := s.repo.Create(ctx, training); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, models.AuditEntityTraining, models.AuditActionCreate, AuditEntry{EntityID: training.ID.Hex(), After: training})
+	return nil
 }
 
 func (s *trainingService) GetTrainingById(ctx context.Context, id string) (*models.Training, error) {
@@ -107,58 +126,103 @@ func (s *trainingService) UpdateTraining(ctx context.Context, id string, req *mo
 		Total:                req.Total,
 	}
 
-	return s.repo.Update(ctx, id, training)
-}
-
-func (s *trainingService) DeleteTraining(ctx context.Context, id string) error {
-	return s.repo.Delete(ctx, id)
-}
-
-func (s *trainingService) ImportFromExcel(ctx context.Context, fileHeader *multipart.FileHeader) (int, error) {
-	file, err := fileHeader.Open()
-	if err != nil {
-		return 0, fmt.Errorf("failed to open file: %v", err)
+	if err := checkScope(ctx, training.Region, training.CabangArea); err != nil {
+		return err
 	}
-	defer file.Close()
 
-	excelFile, err := excelize.OpenReader(file)
+	before, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read Excel file: %v", err)
+		return err
 	}
-	defer excelFile.Close()
 
-	trainingData, err := utils.ParseExcelToTraining(excelFile)
-	if err != nil {
-		return 0, fmt.Errorf("failed to parse Excel data: %v", err)
+	if err := s.repo.Update(ctx, id, training); err != nil {
+		return err
 	}
 
-	if len(trainingData) == 0 {
-		return 0, fmt.Errorf("no valid data found in Excel file")
+	// After dibaca ulang supaya audit mencatat dokumen yang benar-benar tersimpan,
+	// termasuk field yang tidak berasal dari request (created_at, asal import).
+	var after interface{} = training
+	if saved, err := s.repo.FindByID(ctx, id); err == nil {
+		after = saved
+	} else {
+		training.ID = before.ID
+		training.CreatedAt = before.CreatedAt
 	}
+	s.audit.Record(ctx, models.AuditEntityTraining, models.AuditActionUpdate, AuditEntry{EntityID: id, Before: before, After: after})
+	return nil
+}
 
-	err = s.repo.InsertMany(ctx, trainingData)
+func (s *trainingService) DeleteTraining(ctx context.Context, id string) error {
+	before, err := s.repo.FindByID(ctx, id)
 	if err != nil {
-		return 0, fmt.Errorf("failed to insert data: %v", err)
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
 	}
 
-	return len(trainingData), nil
+	s.audit.Record(ctx, models.AuditEntityTraining, models.AuditActionDelete, AuditEntry{EntityID: id, Before: before})
+	return nil
 }
 
-func (s *trainingService) ExportToExcel(ctx context.Context) (*excelize.File, error) {
-	data, _, err := s.repo.FindAll(ctx, 1, 999999)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch data: %v", err)
-	}
+func (s *trainingService) ImportFromExcel(ctx context.Context, file io.ReadSeeker, opts ImportOptions) (*models.ImportReport, error) {
+	return runImport(ctx, s.importSpec(), file, opts)
+}
 
-	excelFile, err := utils.ExportTrainingToExcel(data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create Excel file: %v", err)
+func (s *trainingService) ImportSheets(ctx context.Context, file io.ReadSeeker, fileName string) (*models.ImportWorkbook, error) {
+	return listSheets(s.importSpec(), file, fileName)
+}
+
+func (s *trainingService) ImportTemplate(ctx context.Context) (*excelize.File, error) {
+	return importTemplate(ctx, s.importConfig.Columns, s.repo.Distinct, map[string]string{"Region": "region", "Cabang/Area": "cabang_area", "Bulan": "bulan"})
+}
+
+func (s *trainingService) RollbackImport(ctx context.Context, batchID string, restore bool) (*models.ImportRollback, error) {
+	return rollbackImport(ctx, s.importSpec(), batchID, restore)
+}
+
+func (s *trainingService) importSpec() importSpec[models.Training] {
+	return importSpec[models.Training]{
+		entity: models.AuditEntityTraining,
+		config: s.importConfig,
+		parse: func(table *utils.ImportTable) (*utils.SheetReader[models.Training], error) {
+			return utils.ParseTraining(table, s.importConfig.Columns)
+		},
+		scopeColumn: "Region",
+		scope:       func(d *models.Training) (string, string) { return d.Region, d.CabangArea },
+		id:          func(d *models.Training) string { return d.ID.Hex() },
+		keep: func(d, existing *models.Training) {
+			d.ID = existing.ID
+			d.CreatedAt = existing.CreatedAt
+		},
+		find: s.repo.FindByKeys,
+		count: func(ctx context.Context, filter bson.M) (int64, error) {
+			_, total, err := s.repo.FindWithFilters(ctx, filter, nil, 1, 1)
+			return total, err
+		},
+		insert:  s.repo.InsertMany,
+		update:  s.repo.ReplaceMany,
+		remove:  s.repo.DeleteMatching,
+		source:  func(d *models.Training) *models.ImportSource { return &d.ImportSource },
+		restore: s.repo.Restore,
+		backups: s.backups,
+		audit:   s.audit,
 	}
+}
 
-	return excelFile, nil
+// ExportToExcel mengekspor semua data yang cocok dengan query, dengan urutan yang sama
+// seperti list, memakai kolom terpilih (semua kolom kalau kosong).
+func (s *trainingService) ExportToExcel(ctx context.Context, query DataQuery, columns []string) (*excelize.File, error) {
+	return exportExcel(ctx, trainingQuery.with(s.importConfig.Columns), query, columns, "Training Data", s.repo.ForEach, utils.TrainingRow)
 }
 
-func (s *trainingService) GetTrainingWithFilters(ctx context.Context, filters map[string]string, page, perPage int) (*models.TrainingListResponse, error) {
+// ExportRecords mengekspor data yang cocok dengan query ke CSV, NDJSON, atau JSON.
+func (s *trainingService) ExportRecords(ctx context.Context, query DataQuery, columns []string, open OpenRecordWriter) error {
+	return exportRecords(ctx, trainingQuery.with(s.importConfig.Columns), query, columns, open, s.repo.ForEach, utils.TrainingRow)
+}
+
+func (s *trainingService) GetTrainingWithFilters(ctx context.Context, query DataQuery, page, perPage int) (*models.TrainingListResponse, error) {
 	if page < 1 {
 		page = 1
 	}
@@ -166,14 +230,12 @@ func (s *trainingService) GetTrainingWithFilters(ctx context.Context, filters ma
 		perPage = 10
 	}
 
-	bsonFilters := bson.M{}
-	for key, value := range filters {
-		if value != "" {
-			bsonFilters[key] = bson.M{"$regex": value, "$options": "i"}
-		}
+	filter, sort, err := trainingQuery.with(s.importConfig.Columns).build(query)
+	if err != nil {
+		return nil, err
 	}
 
-	data, total, err := s.repo.FindWithFilters(ctx, bsonFilters, page, perPage)
+	data, total, err := s.repo.FindWithFilters(ctx, filter, sort, page, perPage)
 	if err != nil {
 		return nil, err
 	}
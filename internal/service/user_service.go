@@ -0,0 +1,220 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
+	"github.com/web-dashboard-made-by-renz/backend/pkg/utils"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrUserExists = errors.New("username already exists")
+
+type UserService interface {
+	CreateUser(ctx context.Context, req *models.UserCreateRequest) (*models.User, error)
+	GetAllUsers(ctx context.Context, page, perPage int) (*models.UserListResponse, error)
+	DisableUser(ctx context.Context, id string) error
+	EnableUser(ctx context.Context, id string) error
+	UpdateRole(ctx context.Context, id string, role string) error
+	UpdateScope(ctx context.Context, id string, scope models.DataScope) error
+	ResetPassword(ctx context.Context, id string, password string) error
+	RevokeSessions(ctx context.Context, id string) (int64, error)
+	Unlock(ctx context.Context, id string) error
+	GetLoginAttempts(ctx context.Context, id string, page, perPage int) (*models.LoginAttemptListResponse, error)
+	EnsureBootstrapAdmin(ctx context.Context, username, password string) error
+}
+
+type userService struct {
+	repo        repository.UserRepository
+	sessionRepo repository.SessionRepository
+	loginGuard  LoginGuard
+}
+
+func NewUserService(repo repository.UserRepository, sessionRepo repository.SessionRepository, loginGuard LoginGuard) UserService {
+	return &userService{
+		repo:        repo,
+		sessionRepo: sessionRepo,
+		loginGuard:  loginGuard,
+	}
+}
+
+func (s *userService) CreateUser(ctx context.Context, req *models.UserCreateRequest) (*models.User, error) {
+	passwordHash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.RoleViewer
+	}
+
+	displayName := strings.TrimSpace(req.DisplayName)
+	if displayName == "" {
+		displayName = req.Username
+	}
+
+	user := &models.User{
+		Username:     strings.TrimSpace(req.Username),
+		DisplayName:  displayName,
+		PasswordHash: passwordHash,
+		Role:         role,
+		Status:       models.UserStatusActive,
+		Scope:        normalizeScope(req.Scope),
+	}
+
+	if err := s.repo.Create(ctx, user); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, ErrUserExists
+		}
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *userService) GetAllUsers(ctx context.Context, page, perPage int) (*models.UserListResponse, error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 10
+	}
+
+	data, total, err := s.repo.FindAll(ctx, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+
+	totalPages := int(total) / perPage
+	if int(total)%perPage != 0 {
+		totalPages++
+	}
+
+	return &models.UserListResponse{
+		Data:       data,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages,
+	}, nil
+}
+
+func (s *userService) DisableUser(ctx context.Context, id string) error {
+	if err := s.repo.UpdateStatus(ctx, id, models.UserStatusDisabled); err != nil {
+		return err
+	}
+
+	// User yang dinonaktifkan langsung kehilangan semua session aktifnya.
+	_, err := s.sessionRepo.RevokeAllForUser(ctx, id)
+	return err
+}
+
+func (s *userService) EnableUser(ctx context.Context, id string) error {
+	return s.repo.UpdateStatus(ctx, id, models.UserStatusActive)
+}
+
+func (s *userService) UpdateRole(ctx context.Context, id string, role string) error {
+	if !models.IsValidRole(role) {
+		return fmt.Errorf("invalid role: %s", role)
+	}
+
+	if err := s.repo.UpdateRole(ctx, id, role); err != nil {
+		return err
+	}
+
+	// Token lama masih membawa role sebelumnya, jadi user harus login ulang.
+	_, err := s.sessionRepo.RevokeAllForUser(ctx, id)
+	return err
+}
+
+func (s *userService) UpdateScope(ctx context.Context, id string, scope models.DataScope) error {
+	if err := s.repo.UpdateScope(ctx, id, normalizeScope(scope)); err != nil {
+		return err
+	}
+
+	// Scope lama ikut tersimpan di token, jadi session yang ada dicabut juga.
+	_, err := s.sessionRepo.RevokeAllForUser(ctx, id)
+	return err
+}
+
+func (s *userService) ResetPassword(ctx context.Context, id string, password string) error {
+	passwordHash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdatePassword(ctx, id, passwordHash); err != nil {
+		return err
+	}
+
+	_, err = s.sessionRepo.RevokeAllForUser(ctx, id)
+	return err
+}
+
+func (s *userService) RevokeSessions(ctx context.Context, id string) (int64, error) {
+	if _, err := s.repo.FindByID(ctx, id); err != nil {
+		return 0, err
+	}
+
+	return s.sessionRepo.RevokeAllForUser(ctx, id)
+}
+
+func (s *userService) Unlock(ctx context.Context, id string) error {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return s.loginGuard.Unlock(ctx, user.Username)
+}
+
+func (s *userService) GetLoginAttempts(ctx context.Context, id string, page, perPage int) (*models.LoginAttemptListResponse, error) {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.loginGuard.GetAttempts(ctx, user.Username, page, perPage)
+}
+
+// EnsureBootstrapAdmin membuat user admin pertama kalau collection users masih kosong,
+// supaya ada akun untuk login dan membuat user lain.
+func (s *userService) EnsureBootstrapAdmin(ctx context.Context, username, password string) error {
+	count, err := s.repo.Count(ctx)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	if password == "" {
+		log.Println("Warning: users collection is empty and ADMIN_PASSWORD is not set, nobody can log in")
+		return nil
+	}
+
+	_, err = s.CreateUser(ctx, &models.UserCreateRequest{
+		Username: username,
+		Password: password,
+		Role:     models.RoleAdmin,
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Bootstrap admin user %q created", username)
+	return nil
+}
+
+func normalizeScope(scope models.DataScope) models.DataScope {
+	return models.DataScope{
+		Region: strings.TrimSpace(scope.Region),
+		Cabang: strings.TrimSpace(scope.Cabang),
+	}
+}
@@ -1,13 +1,16 @@
 package function
 
 import (
+	"context"
 	"net/http"
 	"sync"
 
 	"github.com/web-dashboard-made-by-renz/backend/config"
 	"github.com/web-dashboard-made-by-renz/backend/internal/handlers"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
 	"github.com/web-dashboard-made-by-renz/backend/internal/repository"
 	"github.com/web-dashboard-made-by-renz/backend/internal/service"
+	"github.com/web-dashboard-made-by-renz/backend/pkg/utils"
 
 	"github.com/GoogleCloudPlatform/functions-framework-go/functions"
 )
@@ -32,21 +35,108 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 			panic("Failed to connect database: " + err.Error())
 		}
 
-		colorisRepo := repository.NewColorisRepository(db.DB)
-		trainingRepo := repository.NewTrainingRepository(db.DB)
-		selloutRepo := repository.NewSelloutRepository(db.DB)
+		colorisRepo := repository.NewColorisRepository(db.DB, cfg.ColorisImportKey)
+		trainingRepo := repository.NewTrainingRepository(db.DB, cfg.TrainingImportKey)
+		selloutRepo := repository.NewSelloutRepository(db.DB, cfg.SelloutImportKey)
+		userRepo := repository.NewUserRepository(db.DB)
+		sessionRepo := repository.NewSessionRepository(db.DB)
+		loginAttemptRepo := repository.NewLoginAttemptRepository(db.DB)
+		apiKeyRepo := repository.NewAPIKeyRepository(db.DB)
+		signingKeyRepo := repository.NewSigningKeyRepository(db.DB)
+		ssoStateRepo := repository.NewSSOStateRepository(db.DB)
+		auditRepo := repository.NewAuditRepository(db.DB)
+		importJobRepo := repository.NewImportJobRepository(db.DB)
+		importBackupRepo := repository.NewImportBackupRepository(db.DB)
 
-		colorisService := service.NewColorisService(colorisRepo)
-		trainingService := service.NewTrainingService(trainingRepo)
-		selloutService := service.NewSelloutService(selloutRepo)
-		authService := service.NewAuthService(cfg.JWTSecret)
+		colorisImport, err := service.NewImportConfig[models.Coloris](utils.ColorisColumns, cfg.ColorisColumnAliases, cfg.ColorisImportKey, cfg.ColorisImportPeriod)
+		if err != nil {
+			panic("Invalid coloris import config: " + err.Error())
+		}
+		trainingImport, err := service.NewImportConfig[models.Training](utils.TrainingColumns, cfg.TrainingColumnAliases, cfg.TrainingImportKey, cfg.TrainingImportPeriod)
+		if err != nil {
+			panic("Invalid training import config: " + err.Error())
+		}
+		selloutImport, err := service.NewImportConfig[models.Sellout](utils.SelloutColumns, cfg.SelloutColumnAliases, cfg.SelloutImportKey, cfg.SelloutImportPeriod)
+		if err != nil {
+			panic("Invalid sellout import config: " + err.Error())
+		}
+
+		auditService := service.NewAuditService(auditRepo)
+		colorisService := service.NewColorisService(colorisRepo, auditService, importBackupRepo, colorisImport)
+		trainingService := service.NewTrainingService(trainingRepo, auditService, importBackupRepo, trainingImport)
+		selloutService := service.NewSelloutService(selloutRepo, auditService, importBackupRepo, selloutImport)
+		reportService := service.NewReportService(selloutRepo, colorisRepo, trainingRepo)
+		importJobService := service.NewImportJobService(importJobRepo, map[string]service.Importer{
+			models.AuditEntityColoris:  colorisService,
+			models.AuditEntityTraining: trainingService,
+			models.AuditEntitySellout:  selloutService,
+		})
+		signingKeyService := service.NewSigningKeyService(signingKeyRepo, service.SigningKeyConfig{
+			Algorithm:        cfg.JWTSigningAlg,
+			RotationInterval: cfg.JWTKeyRotationInterval,
+			GracePeriod:      cfg.JWTKeyGracePeriod,
+			EncryptionSecret: cfg.JWTKeyEncryptionSecret,
+		})
+		loginGuard := service.NewLoginGuard(loginAttemptRepo, service.LoginGuardPolicy{
+			MaxFailures:      cfg.LoginMaxFailures,
+			MaxFailuresPerIP: cfg.LoginMaxFailuresPerIP,
+			LockoutBase:      cfg.LoginLockoutBase,
+			LockoutMax:       cfg.LoginLockoutMax,
+			FailureWindow:    cfg.LoginFailureWindow,
+		})
+		authService := service.NewAuthService(signingKeyService, service.TokenConfig{
+			Issuer:          cfg.JWTIssuer,
+			Audience:        cfg.JWTAudience,
+			AccessTokenTTL:  cfg.AccessTokenTTL,
+			RefreshTokenTTL: cfg.RefreshTokenTTL,
+		}, userRepo, sessionRepo, loginGuard)
+		userService := service.NewUserService(userRepo, sessionRepo, loginGuard)
+		apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo)
+
+		var identityProviders []service.IdentityProvider
+		if cfg.OIDCIssuerURL != "" {
+			identityProviders = append(identityProviders, service.NewOIDCProvider(service.OIDCProviderConfig{
+				Name:          cfg.OIDCProviderName,
+				IssuerURL:     cfg.OIDCIssuerURL,
+				ClientID:      cfg.OIDCClientID,
+				ClientSecret:  cfg.OIDCClientSecret,
+				RedirectURL:   cfg.OIDCRedirectURL,
+				Scopes:        cfg.OIDCScopes,
+				UsernameClaim: cfg.OIDCUsernameClaim,
+				GroupsClaim:   cfg.OIDCGroupsClaim,
+				RegionClaim:   cfg.OIDCRegionClaim,
+				CabangClaim:   cfg.OIDCCabangClaim,
+			}))
+		}
+		ssoService := service.NewSSOService(identityProviders, service.SSOMapping{
+			RoleMapping:     cfg.OIDCRoleMapping,
+			DefaultRole:     cfg.OIDCDefaultRole,
+			SyncScope:       cfg.OIDCRegionClaim != "" || cfg.OIDCCabangClaim != "",
+			AutoCreateUsers: cfg.OIDCAutoCreateUsers,
+			LinkByUsername:  cfg.OIDCLinkByUsername,
+		}, ssoStateRepo, userRepo, authService, loginGuard)
+
+		if err := signingKeyService.EnsureActiveKey(context.Background()); err != nil {
+			panic("Failed to load JWT signing keys: " + err.Error())
+		}
+
+		if err := userService.EnsureBootstrapAdmin(context.Background(), cfg.AdminUsername, cfg.AdminPassword); err != nil {
+			panic("Failed to bootstrap admin user: " + err.Error())
+		}
 
-		colorisHandler := handlers.NewColorisHandler(colorisService)
-		trainingHandler := handlers.NewTrainingHandler(trainingService)
-		selloutHandler := handlers.NewSelloutHandler(selloutService)
+		colorisHandler := handlers.NewColorisHandler(colorisService, importJobService)
+		trainingHandler := handlers.NewTrainingHandler(trainingService, importJobService)
+		selloutHandler := handlers.NewSelloutHandler(selloutService, importJobService)
 		authHandler := handlers.NewAuthHandler(authService)
+		userHandler := handlers.NewUserHandler(userService)
+		apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+		signingKeyHandler := handlers.NewSigningKeyHandler(signingKeyService)
+		ssoHandler := handlers.NewSSOHandler(ssoService)
+		auditHandler := handlers.NewAuditHandler(auditService)
+		importJobHandler := handlers.NewImportJobHandler(importJobService)
+		reportHandler := handlers.NewReportHandler(reportService)
 
-		router = handlers.SetupRouter(cfg, colorisHandler, trainingHandler, selloutHandler, authHandler)
+		router = handlers.SetupRouter(cfg, authService, apiKeyService, colorisHandler, trainingHandler, selloutHandler, authHandler, userHandler, apiKeyHandler, signingKeyHandler, ssoHandler, auditHandler, importJobHandler, reportHandler)
 	})
 
 	router.ServeHTTP(w, r)
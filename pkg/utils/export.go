@@ -0,0 +1,209 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// UnknownColumnsError dikembalikan kalau kolom yang diminta untuk export tidak dikenal.
+type UnknownColumnsError struct {
+	Columns []string
+}
+
+func (e *UnknownColumnsError) Error() string {
+	return fmt.Sprintf("unknown column(s): %s", strings.Join(e.Columns, ", "))
+}
+
+// ExportColumns adalah kolom yang dipilih untuk export, sesuai urutan permintaan.
+type ExportColumns struct {
+	Columns []Column
+	index   []int
+}
+
+// Lookup mencari kolom berdasarkan field (mis. "nama_colorist") atau nama header
+// beserta aliasnya (mis. "Nama Colorist").
+func (s *ColumnSet) Lookup(name string) (Column, bool) {
+	for _, col := range s.columns {
+		if col.Field == name {
+			return col, true
+		}
+	}
+	if canonical, ok := s.lookup[normalizeHeader(name)]; ok {
+		return s.column(canonical), true
+	}
+	return Column{}, false
+}
+
+// Select memilih kolom export berdasarkan field atau nama header. Tanpa nama, semua
+// kolom dipakai sesuai urutan definisi. Kolom yang disebut dua kali hanya dipakai sekali.
+func (s *ColumnSet) Select(names []string) (*ExportColumns, error) {
+	selected := &ExportColumns{}
+	if len(names) == 0 {
+		for i, col := range s.columns {
+			selected.Columns = append(selected.Columns, col)
+			selected.index = append(selected.index, i)
+		}
+		return selected, nil
+	}
+
+	position := map[string]int{}
+	for i, col := range s.columns {
+		position[col.Name] = i
+	}
+	used := map[int]bool{}
+	var unknown []string
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		col, ok := s.Lookup(name)
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		i := position[col.Name]
+		if used[i] {
+			continue
+		}
+		used[i] = true
+		selected.Columns = append(selected.Columns, col)
+		selected.index = append(selected.index, i)
+	}
+	if len(unknown) > 0 {
+		return nil, &UnknownColumnsError{Columns: unknown}
+	}
+	if len(selected.Columns) == 0 {
+		return s.Select(nil)
+	}
+	return selected, nil
+}
+
+// Names mengembalikan header kolom terpilih.
+func (e *ExportColumns) Names() []string {
+	names := make([]string, len(e.Columns))
+	for i, col := range e.Columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// Row mengambil nilai kolom terpilih dari satu baris lengkap (urutan definisi).
+func (e *ExportColumns) Row(values []interface{}) []interface{} {
+	row := make([]interface{}, len(e.index))
+	for i, index := range e.index {
+		row[i] = values[index]
+	}
+	return row
+}
+
+// ErrExcelRowLimit dikembalikan kalau data export melebihi jumlah baris maksimum satu sheet Excel.
+var ErrExcelRowLimit = fmt.Errorf("data melebihi batas %d baris per sheet Excel", excelize.TotalRows-1)
+
+// ExcelWriter menulis export ke satu sheet lewat excelize.StreamWriter. Baris tidak disimpan
+// sebagai sel di memori; excelize memindahkannya ke file sementara kalau sudah besar, jadi
+// memori tetap terbatas berapa pun jumlah datanya.
+type ExcelWriter struct {
+	file      *excelize.File
+	stream    *excelize.StreamWriter
+	columns   *ExportColumns
+	dateStyle int
+	row       int
+}
+
+// NewExcelWriter membuat file dengan satu sheet dan header abu-abu sesuai kolom terpilih.
+func NewExcelWriter(sheetName string, columns *ExportColumns) (*ExcelWriter, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", sheetName); err != nil {
+		f.Close()
+		return nil, err
+	}
+	w, err := newExcelWriter(f, sheetName, columns)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// newExcelWriter membuka StreamWriter untuk sheet yang sudah ada di f dan menulis header.
+func newExcelWriter(f *excelize.File, sheetName string, columns *ExportColumns) (*ExcelWriter, error) {
+	style, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#E0E0E0"}, Pattern: 1},
+	})
+	if err != nil {
+		return nil, err
+	}
+	dateFormat := "yyyy-mm-dd hh:mm:ss"
+	dateStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat})
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := f.NewStreamWriter(sheetName)
+	if err != nil {
+		return nil, err
+	}
+	// Panes dan lebar kolom harus diatur sebelum baris pertama ditulis.
+	if err := stream.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return nil, err
+	}
+	for i, col := range columns.Columns {
+		if err := stream.SetColWidth(i+1, i+1, float64(max(len(col.Name), 10)+4)); err != nil {
+			return nil, err
+		}
+	}
+
+	header := make([]interface{}, len(columns.Columns))
+	for i, name := range columns.Names() {
+		header[i] = name
+	}
+	if err := stream.SetRow("A1", header, excelize.RowOpts{StyleID: style}); err != nil {
+		return nil, err
+	}
+
+	return &ExcelWriter{file: f, stream: stream, columns: columns, dateStyle: dateStyle, row: 1}, nil
+}
+
+// Write menulis satu baris. values berisi nilai semua kolom sesuai urutan definisi.
+func (w *ExcelWriter) Write(values []interface{}) error {
+	if w.row >= excelize.TotalRows {
+		return ErrExcelRowLimit
+	}
+	w.row++
+	cell, err := excelize.CoordinatesToCellName(1, w.row)
+	if err != nil {
+		return err
+	}
+	row := w.columns.Row(values)
+	for i, value := range row {
+		// Waktu ditulis sebagai tanggal Excel, bukan teks, supaya bisa difilter dan diurutkan.
+		if t, ok := value.(time.Time); ok {
+			if t.IsZero() {
+				row[i] = nil
+			} else {
+				row[i] = excelize.Cell{StyleID: w.dateStyle, Value: t}
+			}
+		}
+	}
+	return w.stream.SetRow(cell, row)
+}
+
+// Finish menutup sheet dan mengembalikan file yang siap ditulis dengan Write. File harus
+// ditutup dengan Close setelah ditulis untuk menghapus file sementara.
+func (w *ExcelWriter) Finish() (*excelize.File, error) {
+	if err := w.stream.Flush(); err != nil {
+		w.file.Close()
+		return nil, err
+	}
+	return w.file, nil
+}
+
+// Close membuang file yang belum selesai ditulis.
+func (w *ExcelWriter) Close() error {
+	return w.file.Close()
+}
@@ -0,0 +1,172 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExportFormat adalah format file export selain Excel.
+type ExportFormat string
+
+const (
+	ExportCSV    ExportFormat = "csv"
+	ExportNDJSON ExportFormat = "ndjson"
+	ExportJSON   ExportFormat = "json"
+)
+
+// ContentType mengembalikan header Content-Type untuk format export.
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportCSV:
+		return "text/csv; charset=utf-8"
+	case ExportNDJSON:
+		return "application/x-ndjson"
+	}
+	return "application/json; charset=utf-8"
+}
+
+// RecordOptions mengatur format CSV. JSON dan NDJSON selalu memakai angka JSON biasa.
+type RecordOptions struct {
+	Delimiter    rune
+	DecimalComma bool
+}
+
+// RecordWriter menulis data export baris demi baris langsung ke writer tujuan.
+type RecordWriter interface {
+	// Write menulis satu baris. values berisi nilai semua kolom sesuai urutan definisi.
+	Write(values []interface{}) error
+	// Close menyelesaikan output tanpa menutup writer tujuan.
+	Close() error
+}
+
+// NewRecordWriter membuat RecordWriter untuk format CSV, NDJSON, atau JSON. CSV memakai
+// header kolom sebagai baris pertama; NDJSON dan JSON memakai Field sebagai key.
+func NewRecordWriter(w io.Writer, format ExportFormat, columns *ExportColumns, opts RecordOptions) (RecordWriter, error) {
+	switch format {
+	case ExportCSV:
+		return newCSVRecordWriter(w, columns, opts)
+	case ExportNDJSON, ExportJSON:
+		return &jsonRecordWriter{w: bufio.NewWriter(w), columns: columns, array: format == ExportJSON}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+type csvRecordWriter struct {
+	w            *csv.Writer
+	columns      *ExportColumns
+	decimalComma bool
+}
+
+func newCSVRecordWriter(w io.Writer, columns *ExportColumns, opts RecordOptions) (*csvRecordWriter, error) {
+	writer := csv.NewWriter(w)
+	if opts.Delimiter != 0 {
+		writer.Comma = opts.Delimiter
+	}
+	if err := writer.Write(columns.Names()); err != nil {
+		return nil, err
+	}
+	return &csvRecordWriter{w: writer, columns: columns, decimalComma: opts.DecimalComma}, nil
+}
+
+func (c *csvRecordWriter) Write(values []interface{}) error {
+	row := c.columns.Row(values)
+	record := make([]string, len(row))
+	for i, value := range row {
+		record[i] = formatRecordValue(value, c.decimalComma)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvRecordWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// formatRecordValue mengubah nilai export menjadi teks CSV. Angka tidak memakai pemisah
+// ribuan supaya bisa dibaca ulang oleh tool lain.
+func formatRecordValue(value interface{}, decimalComma bool) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		s := strconv.FormatFloat(v, 'f', -1, 64)
+		if decimalComma {
+			s = strings.Replace(s, ".", ",", 1)
+		}
+		return s
+	case time.Time:
+		if v.IsZero() {
+			return ""
+		}
+		return v.Format("2006-01-02 15:04:05")
+	}
+	return fmt.Sprint(value)
+}
+
+// jsonRecordWriter menulis satu object per baris (NDJSON) atau satu array JSON. Key
+// ditulis sesuai urutan kolom terpilih.
+type jsonRecordWriter struct {
+	w       *bufio.Writer
+	columns *ExportColumns
+	array   bool
+	rows    int
+	buf     bytes.Buffer
+}
+
+func (j *jsonRecordWriter) Write(values []interface{}) error {
+	j.buf.Reset()
+	j.buf.WriteByte('{')
+	for i, value := range j.columns.Row(values) {
+		if i > 0 {
+			j.buf.WriteByte(',')
+		}
+		key, err := json.Marshal(j.columns.Columns[i].Field)
+		if err != nil {
+			return err
+		}
+		if t, ok := value.(time.Time); ok && t.IsZero() {
+			value = nil
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		j.buf.Write(key)
+		j.buf.WriteByte(':')
+		j.buf.Write(encoded)
+	}
+	j.buf.WriteByte('}')
+
+	switch {
+	case !j.array:
+		j.buf.WriteByte('\n')
+	case j.rows == 0:
+		j.w.WriteString("[\n")
+	default:
+		j.w.WriteString(",\n")
+	}
+	j.rows++
+	_, err := j.w.Write(j.buf.Bytes())
+	return err
+}
+
+func (j *jsonRecordWriter) Close() error {
+	if j.array {
+		if j.rows == 0 {
+			j.w.WriteString("[")
+		}
+		j.w.WriteString("\n]\n")
+	}
+	return j.w.Flush()
+}
@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ColumnType adalah jenis isi kolom, dipakai untuk format dan petunjuk di template import.
+type ColumnType int
+
+const (
+	ColumnText ColumnType = iota
+	// ColumnNumber boleh memakai pemisah ribuan dan awalan Rp.
+	ColumnNumber
+	// ColumnScore adalah angka atau nilai seperti "80/100".
+	ColumnScore
+	ColumnInteger
+	// ColumnMonth adalah nomor bulan 1-12.
+	ColumnMonth
+	ColumnTimestamp
+)
+
+// Column adalah definisi satu kolom file import. Name dipakai sebagai header saat
+// export; Aliases adalah nama lain yang juga diterima saat import. Field adalah nama
+// field JSON/BSON-nya, dipakai untuk memilih kolom export dan urutan data. Type dan
+// Example dipakai untuk membuat template import.
+type Column struct {
+	Name     string
+	Field    string
+	Aliases  []string
+	Required bool
+	Type     ColumnType
+	Example  string
+}
+
+// ColumnSet memetakan header file ke kolom berdasarkan nama, bukan posisi,
+// sehingga urutan kolom di file bebas dan kolom tambahan diabaikan.
+type ColumnSet struct {
+	columns []Column
+	lookup  map[string]string
+}
+
+// MissingColumnsError dikembalikan kalau header wajib tidak ada di file.
+type MissingColumnsError struct {
+	Columns []string
+}
+
+func (e *MissingColumnsError) Error() string {
+	return fmt.Sprintf("missing required column(s): %s", strings.Join(e.Columns, ", "))
+}
+
+func NewColumnSet(columns []Column) *ColumnSet {
+	s := &ColumnSet{columns: columns, lookup: map[string]string{}}
+	for _, col := range columns {
+		s.lookup[normalizeHeader(col.Name)] = col.Name
+		for _, alias := range col.Aliases {
+			s.lookup[normalizeHeader(alias)] = col.Name
+		}
+	}
+	return s
+}
+
+// Columns mengembalikan salinan definisi kolom sesuai urutan.
+func (s *ColumnSet) Columns() []Column {
+	return append([]Column{}, s.columns...)
+}
+
+// Names mengembalikan nama kolom sesuai urutan definisi.
+func (s *ColumnSet) Names() []string {
+	names := make([]string, len(s.columns))
+	for i, col := range s.columns {
+		names[i] = col.Name
+	}
+	return names
+}
+
+// WithAliases mengembalikan salinan ColumnSet dengan alias tambahan dalam bentuk
+// alias -> nama kolom, mis. dari konfigurasi.
+func (s *ColumnSet) WithAliases(aliases map[string]string) (*ColumnSet, error) {
+	columns := make([]Column, len(s.columns))
+	copy(columns, s.columns)
+
+	for alias, name := range aliases {
+		found := false
+		for i := range columns {
+			if normalizeHeader(columns[i].Name) == normalizeHeader(name) {
+				columns[i].Aliases = append(append([]string{}, columns[i].Aliases...), alias)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("alias %q refers to unknown column %q", alias, name)
+		}
+	}
+
+	return NewColumnSet(columns), nil
+}
+
+func (s *ColumnSet) column(name string) Column {
+	for _, col := range s.columns {
+		if col.Name == name {
+			return col
+		}
+	}
+	panic(fmt.Sprintf("utils: unknown import column %q", name))
+}
+
+// mapHeader mencari posisi setiap kolom di baris header. Header yang tidak dikenal
+// dikembalikan di ignored; kalau header yang sama muncul dua kali, yang pertama dipakai.
+func (s *ColumnSet) mapHeader(header []string) (index map[string]int, ignored []string, err error) {
+	index = map[string]int{}
+	for i, cell := range header {
+		cell = strings.TrimSpace(cell)
+		if cell == "" {
+			continue
+		}
+		name, ok := s.lookup[normalizeHeader(cell)]
+		if !ok {
+			ignored = append(ignored, cell)
+			continue
+		}
+		if _, seen := index[name]; !seen {
+			index[name] = i
+		}
+	}
+
+	var missing []string
+	for _, col := range s.columns {
+		if _, ok := index[col.Name]; !ok && col.Required {
+			missing = append(missing, col.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, nil, &MissingColumnsError{Columns: missing}
+	}
+
+	return index, ignored, nil
+}
+
+// normalizeHeader membuat pencocokan header tidak peka huruf besar/kecil dan spasi berlebih.
+func normalizeHeader(header string) string {
+	return strings.Join(strings.Fields(strings.ToLower(header)), " ")
+}
+
+var (
+	ColorisColumns = NewColumnSet([]Column{
+		{Name: "Timestamp", Field: "timestamp", Aliases: []string{"Tanggal", "Waktu"}, Required: true, Type: ColumnTimestamp, Example: "2025-01-17 14:47:50"},
+		{Name: "Bulan", Field: "bulan", Required: true, Example: "17 Januari 2025"},
+		{Name: "Region", Field: "region", Aliases: []string{"Reg", "Regional"}, Required: true, Example: "Jakarta"},
+		{Name: "Cabang", Field: "cabang", Aliases: []string{"Cabang/Area", "Cabang Area"}, Required: true, Example: "Cabang A"},
+		{Name: "Materi", Field: "materi", Aliases: []string{"Materi Pelatihan"}, Example: "Coloring Dasar"},
+		{Name: "Nama Atasan Langsung", Field: "nama_atasan_langsung", Aliases: []string{"Atasan Langsung", "Nama Atasan"}, Example: "Budi Santoso"},
+		{Name: "Nama Toko", Field: "nama_toko", Aliases: []string{"Toko"}, Example: "Toko Cantik"},
+		{Name: "Nama Lengkap Sesuai KTP", Field: "nama_lengkap_sesuai_ktp", Aliases: []string{"Nama Lengkap", "Nama Sesuai KTP"}, Required: true, Example: "Siti Aminah"},
+		{Name: "Nilai PG", Field: "nilai_pg", Aliases: []string{"Nilai Pilihan Ganda"}, Type: ColumnScore, Example: "80"},
+		{Name: "Nilai Akhir", Field: "nilai_akhir", Type: ColumnNumber, Example: "85"},
+		{Name: "Total", Field: "total", Type: ColumnNumber, Example: "165"},
+	})
+
+	TrainingColumns = NewColumnSet([]Column{
+		{Name: "Timestamp", Field: "timestamp", Aliases: []string{"Tanggal", "Waktu"}, Required: true, Type: ColumnTimestamp, Example: "2025-01-17 14:47:50"},
+		{Name: "Bulan", Field: "bulan", Required: true, Example: "17 Januari 2025"},
+		{Name: "Region", Field: "region", Aliases: []string{"Reg", "Regional"}, Required: true, Example: "Jakarta"},
+		{Name: "Cabang/Area", Field: "cabang_area", Aliases: []string{"Cabang Area", "Cabang", "Area"}, Required: true, Example: "Cabang A"},
+		{Name: "Nama Atasan Langsung", Field: "nama_atasan_langsung", Aliases: []string{"Atasan Langsung", "Nama Atasan"}, Example: "Budi Santoso"},
+		{Name: "Materi Pelatihan", Field: "materi_pelatihan", Aliases: []string{"Materi"}, Example: "Coloring Lanjutan"},
+		{Name: "Nama Lengkap Sesuai KTP", Field: "nama_lengkap_sesuai_ktp", Aliases: []string{"Nama Lengkap", "Nama Sesuai KTP"}, Required: true, Example: "Siti Aminah"},
+		{Name: "Jabatan", Field: "jabatan", Example: "Colorist"},
+		{Name: "Total Nilai", Field: "total_nilai", Type: ColumnScore, Example: "80"},
+		{Name: "Nilai Essay", Field: "nilai_essay", Aliases: []string{"Nilai Esai"}, Type: ColumnNumber, Example: "15"},
+		{Name: "Total", Field: "total", Type: ColumnNumber, Example: "95"},
+	})
+
+	SelloutColumns = NewColumnSet([]Column{
+		{Name: "Tahun", Field: "tahun", Required: true, Type: ColumnInteger, Example: "2025"},
+		{Name: "Bulan", Field: "bulan", Required: true, Type: ColumnMonth, Example: "1"},
+		{Name: "Reg", Field: "reg", Aliases: []string{"Region", "Regional"}, Required: true, Example: "Jakarta"},
+		{Name: "Cabang", Field: "cabang", Aliases: []string{"Cabang/Area", "Cabang Area"}, Required: true, Example: "Cabang A"},
+		{Name: "Outlet", Field: "outlet", Aliases: []string{"Nama Outlet", "Toko"}, Required: true, Example: "Salon Indah"},
+		{Name: "Area Cover", Field: "area_cover", Example: "Jakarta Selatan"},
+		{Name: "MOS/SS", Field: "mos_ss", Aliases: []string{"MOS SS", "MOS / SS"}, Example: "MOS"},
+		{Name: "Nama Colorist", Field: "nama_colorist", Aliases: []string{"Colorist"}, Required: true, Example: "Siti Aminah"},
+		{Name: "No Reg", Field: "no_reg", Aliases: []string{"No. Reg", "Nomor Registrasi"}, Required: true, Example: "CLR-0001"},
+		{Name: "Tanggal Bergabung", Field: "tanggal_bergabung", Aliases: []string{"Tgl Bergabung"}, Example: "2023-06-01"},
+		{Name: "Masa Kerja", Field: "masa_kerja", Type: ColumnNumber, Example: "19"},
+		{Name: "CHL", Field: "chl", Required: true, Example: "CHL 1"},
+		{Name: "Wilayah", Field: "wilayah", Example: "Barat"},
+		{Name: "Target Sellout", Field: "target_sellout", Aliases: []string{"Target"}, Type: ColumnNumber, Example: "15000000"},
+		{Name: "Sellout TT", Field: "sellout_tt", Type: ColumnNumber, Example: "10000000"},
+		{Name: "Sellout RM", Field: "sellout_rm", Type: ColumnNumber, Example: "4000000"},
+		{Name: "Primafix", Field: "primafix", Type: ColumnNumber, Example: "500000"},
+		{Name: "Total Sellout", Field: "total_sellout", Type: ColumnNumber, Example: "14500000"},
+	})
+)
@@ -0,0 +1,471 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	ImportFormatXLSX = "xlsx"
+	ImportFormatXLS  = "xls"
+	ImportFormatCSV  = "csv"
+	ImportFormatTSV  = "tsv"
+)
+
+var (
+	// ErrUnsupportedFormat dikembalikan (lewat UnsupportedFormatError) kalau isi file
+	// bukan format yang bisa diimport.
+	ErrUnsupportedFormat = errors.New("unsupported file format")
+	// ErrUnreadableFile dikembalikan kalau formatnya dikenali tetapi isinya rusak.
+	ErrUnreadableFile = errors.New("file cannot be read")
+	// ErrSheetNotFound dikembalikan (lewat SheetNotFoundError) kalau sheet yang dipilih
+	// tidak ada di file.
+	ErrSheetNotFound = errors.New("sheet not found")
+)
+
+// UnsupportedFormatError menyebutkan format yang dikenali tetapi tidak bisa dibaca.
+// Format kosong berarti isi file tidak dikenali sama sekali.
+type UnsupportedFormatError struct {
+	Format string
+}
+
+func (e *UnsupportedFormatError) Error() string {
+	if e.Format == "" {
+		return "unrecognized file format, use .xlsx, .xls, .csv or .tsv"
+	}
+	return fmt.Sprintf("%s is not supported, save the file as .xlsx, .xls, .csv or .tsv", e.Format)
+}
+
+func (e *UnsupportedFormatError) Is(target error) bool {
+	return target == ErrUnsupportedFormat
+}
+
+// SheetNotFoundError menyebutkan sheet yang dipilih beserta daftar sheet yang ada.
+type SheetNotFoundError struct {
+	Selection SheetSelection
+	Sheets    []string
+}
+
+func (e *SheetNotFoundError) Error() string {
+	if len(e.Sheets) == 0 {
+		return fmt.Sprintf("%s not found, CSV and TSV files have no sheets", e.Selection)
+	}
+	return fmt.Sprintf("%s not found, available sheets: %s", e.Selection, strings.Join(e.Sheets, ", "))
+}
+
+func (e *SheetNotFoundError) Is(target error) bool {
+	return target == ErrSheetNotFound
+}
+
+// SheetSelection memilih sheet workbook yang diimport. Nilai kosong berarti sheet pertama.
+// Name didahulukan dari Index (dimulai dari 0), dan All mengimport semua sheet.
+type SheetSelection struct {
+	Name  string
+	Index int
+	All   bool
+}
+
+func (s SheetSelection) String() string {
+	switch {
+	case s.All:
+		return "all sheets"
+	case s.Name != "":
+		return strconv.Quote(s.Name)
+	}
+	return fmt.Sprintf("sheet index %d", s.Index)
+}
+
+// Resolve mengembalikan index sheet yang dipilih dari daftar nama sheet. Nama dicocokkan
+// persis dulu, lalu tanpa membedakan huruf besar/kecil.
+func (s SheetSelection) Resolve(sheets []string) ([]int, error) {
+	if s.All {
+		indexes := make([]int, len(sheets))
+		for i := range sheets {
+			indexes[i] = i
+		}
+		return indexes, nil
+	}
+
+	if s.Name != "" {
+		name := strings.TrimSpace(s.Name)
+		for i, sheet := range sheets {
+			if sheet == name {
+				return []int{i}, nil
+			}
+		}
+		for i, sheet := range sheets {
+			if strings.EqualFold(strings.TrimSpace(sheet), name) {
+				return []int{i}, nil
+			}
+		}
+		return nil, &SheetNotFoundError{Selection: s, Sheets: sheets}
+	}
+
+	if s.Index < 0 || s.Index >= len(sheets) {
+		return nil, &SheetNotFoundError{Selection: s, Sheets: sheets}
+	}
+	return []int{s.Index}, nil
+}
+
+var (
+	zipSignature = []byte("PK\x03\x04")
+	oleSignature = []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1")
+	utf8BOM      = []byte("\xef\xbb\xbf")
+)
+
+// knownSignatures adalah format lain yang sering salah diupload, supaya pesan errornya jelas.
+var knownSignatures = []struct {
+	prefix []byte
+	format string
+}{
+	{[]byte("%PDF"), "PDF"},
+	{[]byte("\xff\xfe"), "UTF-16 text (Excel \"Unicode Text\")"},
+	{[]byte("\xfe\xff"), "UTF-16 text"},
+	{[]byte("{\\rtf"), "RTF"},
+	{[]byte("\x89PNG"), "PNG image"},
+	{[]byte("\xff\xd8\xff"), "JPEG image"},
+}
+
+// DetectImportFormat menebak format file dari beberapa byte pertama isinya, bukan dari
+// nama file atau Content-Type. Ekstensi hanya dipakai untuk membedakan TSV dari CSV.
+// Format yang tidak bisa dibaca dikembalikan sebagai *UnsupportedFormatError.
+func DetectImportFormat(head []byte, filename string) (string, error) {
+	switch {
+	case bytes.HasPrefix(head, zipSignature):
+		// File OpenDocument menyimpan mimetype-nya sebagai entry pertama zip.
+		if bytes.Contains(head[:min(len(head), 128)], []byte("application/vnd.oasis.opendocument")) {
+			return "", &UnsupportedFormatError{Format: "OpenDocument spreadsheet (.ods)"}
+		}
+		return ImportFormatXLSX, nil
+	case bytes.HasPrefix(head, oleSignature):
+		return ImportFormatXLS, nil
+	}
+
+	for _, known := range knownSignatures {
+		if bytes.HasPrefix(head, known.prefix) {
+			return "", &UnsupportedFormatError{Format: known.format}
+		}
+	}
+	if !isText(head) {
+		return "", &UnsupportedFormatError{}
+	}
+
+	if strings.EqualFold(filepath.Ext(filename), ".tsv") {
+		return ImportFormatTSV, nil
+	}
+	if detectDelimiter(head) == '\t' {
+		return ImportFormatTSV, nil
+	}
+	return ImportFormatCSV, nil
+}
+
+// importUnzipXMLSizeLimit adalah batas ukuran XML worksheet yang dibuka di memori. Sheet
+// yang lebih besar diekstrak excelize ke file sementara dan dibaca bertahap.
+const importUnzipXMLSizeLimit = 8 << 20
+
+// ImportFile adalah file import yang sudah dibuka. Isi sheet tidak dimuat ke memori; setiap
+// sheet dibaca baris per baris lewat Table dan bisa dibaca ulang dari awal.
+type ImportFile struct {
+	Format string
+	// Sheets adalah nama sheet yang dipilih, sesuai urutan di workbook. File CSV/TSV
+	// berisi satu sheet tanpa nama.
+	Sheets []string
+	// Numbers memaksa format angka CSV/TSV (?decimal=); kosong berarti ditebak.
+	Numbers NumberFormat
+
+	source   io.ReadSeeker
+	filename string
+	excel    *excelize.File
+	xls      *xlsWorkbook
+	indexes  []int
+}
+
+// OpenImportFile mengenali format file dari isinya lalu membuka sheet yang dipilih.
+// File .xls dibaca utuh ke memori karena formatnya dibatasi 65.536 baris per sheet.
+func OpenImportFile(r io.ReadSeeker, filename string, selection SheetSelection) (*ImportFile, error) {
+	head := make([]byte, 4096)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, err
+	}
+	format, err := DetectImportFormat(head[:n], filename)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	f := &ImportFile{Format: format, source: r, filename: filename}
+	var names []string
+	switch format {
+	case ImportFormatXLSX:
+		f.excel, err = excelize.OpenReader(r, excelize.Options{UnzipXMLSizeLimit: importUnzipXMLSizeLimit})
+		if err != nil {
+			// File .xlsx yang diproteksi password disimpan sebagai dokumen OLE, jadi yang
+			// sampai di sini adalah zip yang bukan workbook OOXML atau file yang rusak.
+			return nil, fmt.Errorf("%w: %v", ErrUnreadableFile, err)
+		}
+		names = f.excel.GetSheetList()
+	case ImportFormatXLS:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		if f.xls, err = openXLS(data); err != nil {
+			return nil, err
+		}
+		names = f.xls.SheetNames()
+	default:
+		if !selection.All && (selection.Name != "" || selection.Index != 0) {
+			return nil, &SheetNotFoundError{Selection: selection}
+		}
+		f.Sheets = []string{""}
+		return f, nil
+	}
+
+	if len(names) == 0 {
+		f.Close()
+		return nil, fmt.Errorf("no sheets found in Excel file")
+	}
+	f.indexes, err = selection.Resolve(names)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	for _, i := range f.indexes {
+		f.Sheets = append(f.Sheets, names[i])
+	}
+	return f, nil
+}
+
+// Table membuka sheet ke-i dari Sheets untuk dibaca dari baris pertama.
+func (f *ImportFile) Table(i int) (*ImportTable, error) {
+	switch f.Format {
+	case ImportFormatXLSX:
+		rows, err := f.excel.Rows(f.Sheets[i])
+		if err != nil {
+			return nil, err
+		}
+		return &ImportTable{Format: f.Format, Sheet: f.Sheets[i], Numbers: NumberFormatDot, rows: &excelRows{rows: rows}}, nil
+	case ImportFormatXLS:
+		rows, err := f.xls.Rows(f.indexes[i])
+		if err != nil {
+			return nil, err
+		}
+		return &ImportTable{Format: f.Format, Sheet: f.Sheets[i], Numbers: NumberFormatDot, rows: &sliceRows{rows: rows}}, nil
+	}
+
+	if _, err := f.source.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	table := newDelimitedTable(f.source, f.filename)
+	if f.Numbers != NumberFormatAuto {
+		table.Numbers = f.Numbers
+	}
+	return table, nil
+}
+
+// Close menghapus file sementara yang dibuat saat membaca workbook.
+func (f *ImportFile) Close() error {
+	if f.excel != nil {
+		return f.excel.Close()
+	}
+	return nil
+}
+
+// ListImportSheets mengembalikan format file dan nama sheet-nya tanpa membaca isi sheet.
+// File CSV/TSV tidak punya sheet.
+func ListImportSheets(r io.ReadSeeker, filename string) (string, []string, error) {
+	f, err := OpenImportFile(r, filename, SheetSelection{All: true})
+	if err != nil {
+		return "", nil, err
+	}
+	defer f.Close()
+
+	if f.excel == nil && f.xls == nil {
+		return f.Format, nil, nil
+	}
+	return f.Format, f.Sheets, nil
+}
+
+// ImportTable membaca isi satu sheet (atau file CSV/TSV) sebagai teks per sel, baris per
+// baris. Baris pertama adalah header.
+type ImportTable struct {
+	Format string
+	// Sheet adalah nama sheet di workbook; kosong untuk CSV/TSV.
+	Sheet string
+	// Numbers adalah format angka di sel teks. Sheet Excel selalu NumberFormatDot;
+	// CSV/TSV ditebak dari pemisah kolom kecuali ImportFile.Numbers diisi.
+	Numbers NumberFormat
+
+	rows rowSource
+}
+
+type rowSource interface {
+	next() ([]string, error)
+	close() error
+}
+
+// Next mengembalikan baris berikutnya, atau io.EOF kalau sheet sudah habis.
+func (t *ImportTable) Next() ([]string, error) {
+	return t.rows.next()
+}
+
+func (t *ImportTable) Close() error {
+	return t.rows.close()
+}
+
+type excelRows struct {
+	rows *excelize.Rows
+}
+
+func (r *excelRows) next() ([]string, error) {
+	if !r.rows.Next() {
+		if err := r.rows.Error(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnreadableFile, err)
+		}
+		return nil, io.EOF
+	}
+	return r.rows.Columns()
+}
+
+func (r *excelRows) close() error {
+	return r.rows.Close()
+}
+
+type sliceRows struct {
+	rows [][]string
+	pos  int
+}
+
+func (r *sliceRows) next() ([]string, error) {
+	if r.pos >= len(r.rows) {
+		return nil, io.EOF
+	}
+	r.pos++
+	return r.rows[r.pos-1], nil
+}
+
+func (r *sliceRows) close() error {
+	return nil
+}
+
+// csvRows membaca CSV/TSV per record. Sel yang bukan UTF-8 (mis. CSV dari Excel Windows)
+// dibaca sebagai Latin-1.
+type csvRows struct {
+	reader *csv.Reader
+}
+
+func (r *csvRows) next() ([]string, error) {
+	record, err := r.reader.Read()
+	if err == io.EOF {
+		return nil, io.EOF
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnreadableFile, err)
+	}
+	for i, field := range record {
+		if !utf8.ValidString(field) {
+			record[i] = string(latin1ToUTF8([]byte(field)))
+		}
+	}
+	return record, nil
+}
+
+func (r *csvRows) close() error {
+	return nil
+}
+
+// newDelimitedTable membaca CSV/TSV. BOM UTF-8 dibuang dan pemisah kolom ditebak dari
+// header. File dengan pemisah titik koma berasal dari locale Indonesia, jadi angkanya
+// memakai koma sebagai desimal.
+func newDelimitedTable(r io.Reader, filename string) *ImportTable {
+	buffered := bufio.NewReaderSize(r, 64<<10)
+	if bom, _ := buffered.Peek(len(utf8BOM)); bytes.Equal(bom, utf8BOM) {
+		buffered.Discard(len(utf8BOM))
+	}
+	head, _ := buffered.Peek(4096)
+
+	delimiter := detectDelimiter(head)
+	if strings.EqualFold(filepath.Ext(filename), ".tsv") {
+		delimiter = '\t'
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	format := ImportFormatCSV
+	if delimiter == '\t' {
+		format = ImportFormatTSV
+	}
+
+	// File dengan pemisah titik koma berasal dari Excel berlocale Indonesia, jadi pasti
+	// memakai koma desimal. Di file lain format angka ditebak per nilai.
+	numbers := NumberFormatAuto
+	if delimiter == ';' {
+		numbers = NumberFormatComma
+	}
+	return &ImportTable{Format: format, Numbers: numbers, rows: &csvRows{reader: reader}}
+}
+
+// detectDelimiter memilih tab, titik koma, atau koma yang paling sering muncul di baris
+// pertama di luar tanda kutip. Kalau tidak ada, dianggap koma.
+func detectDelimiter(data []byte) rune {
+	counts := map[rune]int{}
+	quoted := false
+	for _, ch := range string(bytes.TrimPrefix(data, utf8BOM)) {
+		if ch == '"' {
+			quoted = !quoted
+			continue
+		}
+		if quoted {
+			continue
+		}
+		if ch == '\n' || ch == '\r' {
+			break
+		}
+		counts[ch]++
+	}
+
+	delimiter := ','
+	for _, candidate := range []rune{';', '\t'} {
+		if counts[candidate] > counts[delimiter] {
+			delimiter = candidate
+		}
+	}
+	return delimiter
+}
+
+// isText menganggap data sebagai teks kalau tidak ada byte kontrol selain tab dan baris baru.
+func isText(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+	for _, b := range data {
+		if b < 0x20 && b != '\t' && b != '\n' && b != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+func latin1ToUTF8(data []byte) []byte {
+	runes := make([]rune, len(data))
+	for i, b := range data {
+		runes[i] = rune(b)
+	}
+	return []byte(string(runes))
+}
@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDelimitedImportNumberFormat(t *testing.T) {
+	const data = "Tahun,Bulan,Reg,Cabang,Outlet,Nama Colorist,No Reg,CHL,Target Sellout,Total Sellout\n" +
+		"2025,3,Jakarta,Cabang A,Salon Indah,Siti,CLR-0001,CHL 1,1.500,12.5\n"
+
+	tests := []struct {
+		name    string
+		numbers NumberFormat
+		target  float64
+		total   float64
+	}{
+		{"auto", NumberFormatAuto, 1500, 12.5},
+		{"dot", NumberFormatDot, 1.5, 12.5},
+		{"comma", NumberFormatComma, 1500, 12.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book, err := OpenImportFile(strings.NewReader(data), "sellout.csv", SheetSelection{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer book.Close()
+			book.Numbers = tt.numbers
+
+			table, err := book.Table(0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer table.Close()
+			reader, err := ParseSellout(table, SelloutColumns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			row, ok, err := reader.Next()
+			if err != nil || !ok {
+				t.Fatalf("Next() = %v, %v", ok, err)
+			}
+			if len(row.Result.Errors) > 0 {
+				t.Fatalf("row errors: %+v", row.Result.Errors)
+			}
+			if row.Record.TargetSellout != tt.target || row.Record.TotalSellout != tt.total {
+				t.Errorf("target, total = %v, %v; want %v, %v", row.Record.TargetSellout, row.Record.TotalSellout, tt.target, tt.total)
+			}
+		})
+	}
+}
@@ -0,0 +1,290 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+)
+
+// ParsedRow adalah satu record hasil parsing beserta nomor baris dan hasil validasinya.
+type ParsedRow[T any] struct {
+	Row    int
+	Record T
+	Result models.ImportRowResult
+}
+
+func blankRow[T any](row int) ParsedRow[T] {
+	return ParsedRow[T]{
+		Row:    row,
+		Result: models.ImportRowResult{Row: row, Status: models.ImportRowSkipped, Reason: "baris kosong"},
+	}
+}
+
+var errEmptyNumber = errors.New("empty number")
+
+// SheetReader membaca dan memvalidasi baris satu sheet satu per satu, jadi isi sheet
+// tidak pernah dimuat utuh ke memori. IgnoredColumns berisi header yang tidak dikenal
+// dan tidak ikut dibaca.
+type SheetReader[T any] struct {
+	IgnoredColumns []string
+
+	table   *ImportTable
+	columns *ColumnSet
+	index   map[string]int
+	build   func(r *rowReader) T
+	row     int
+	// blanks adalah jumlah baris kosong sebelum pending. Baris kosong baru dilaporkan
+	// kalau ada baris berisi sesudahnya, sama seperti GetRows yang membuang baris kosong
+	// di akhir sheet (mis. baris yang hanya diberi format).
+	blanks  int
+	pending *ParsedRow[T]
+}
+
+// Next mengembalikan baris berikutnya. ok bernilai false kalau sheet sudah habis.
+func (s *SheetReader[T]) Next() (row ParsedRow[T], ok bool, err error) {
+	if s.pending == nil {
+		if ok, err := s.fill(); !ok || err != nil {
+			return row, false, err
+		}
+	}
+
+	if s.blanks > 0 {
+		row = blankRow[T](s.pending.Row - s.blanks)
+		s.blanks--
+		return row, true, nil
+	}
+	row = *s.pending
+	s.pending = nil
+	return row, true, nil
+}
+
+// fill membaca sampai baris berisi berikutnya dan menyimpannya di pending.
+func (s *SheetReader[T]) fill() (bool, error) {
+	for {
+		cells, err := s.table.Next()
+		if err == io.EOF {
+			s.blanks = 0
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		s.row++
+
+		r := newRowReader(cells, s.columns, s.index, s.table.Numbers)
+		if r.isBlank() {
+			s.blanks++
+			continue
+		}
+
+		record := s.build(r)
+		s.pending = &ParsedRow[T]{Row: s.row, Record: record, Result: r.result(s.row)}
+		return true, nil
+	}
+}
+
+// rowReader membaca sel satu baris berdasarkan nama kolom sambil mengumpulkan error
+// per kolom, supaya satu baris bisa melaporkan semua kolom yang salah sekaligus.
+type rowReader struct {
+	cells   []string
+	columns *ColumnSet
+	index   map[string]int
+	numbers NumberFormat
+	errors  []models.ImportFieldError
+}
+
+func newRowReader(cells []string, columns *ColumnSet, index map[string]int, numbers NumberFormat) *rowReader {
+	return &rowReader{cells: cells, columns: columns, index: index, numbers: numbers}
+}
+
+// cell mengembalikan isi kolom; kolom opsional yang tidak ada di file dianggap kosong.
+func (r *rowReader) cell(name string) string {
+	i, ok := r.index[name]
+	if !ok || i >= len(r.cells) {
+		return ""
+	}
+	return strings.TrimSpace(r.cells[i])
+}
+
+// isBlank hanya melihat kolom yang dikenal, jadi baris yang cuma berisi kolom tambahan
+// tetap dianggap kosong.
+func (r *rowReader) isBlank() bool {
+	for name := range r.index {
+		if r.cell(name) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *rowReader) fail(name, value, reason string) {
+	r.errors = append(r.errors, models.ImportFieldError{
+		Column: name,
+		Value:  value,
+		Reason: reason,
+	})
+}
+
+// empty mencatat error kalau kolom wajib kosong.
+func (r *rowReader) empty(name, value string) bool {
+	if value != "" {
+		return false
+	}
+	if r.columns.column(name).Required {
+		r.fail(name, "", "wajib diisi")
+	}
+	return true
+}
+
+func (r *rowReader) text(name string) string {
+	value := r.cell(name)
+	r.empty(name, value)
+	return value
+}
+
+func (r *rowReader) number(name string) float64 {
+	value := r.cell(name)
+	if r.empty(name, value) {
+		return 0
+	}
+
+	n, err := parseNumber(value, r.numbers)
+	if err != nil {
+		r.fail(name, value, "bukan angka yang valid")
+	}
+	return n
+}
+
+// score membaca nilai seperti "80/100" (diambil 80) atau angka biasa.
+func (r *rowReader) score(name string) float64 {
+	value := r.cell(name)
+	if before, _, ok := strings.Cut(value, "/"); ok {
+		if n, err := parseNumber(before, r.numbers); err == nil {
+			return n
+		}
+		r.fail(name, value, "bukan nilai yang valid")
+		return 0
+	}
+	return r.number(name)
+}
+
+func (r *rowReader) integer(name string) int {
+	value := r.cell(name)
+	failed := len(r.errors)
+	n := r.number(name)
+	if len(r.errors) == failed && n != float64(int(n)) {
+		r.fail(name, value, "harus bilangan bulat")
+		return 0
+	}
+	return int(n)
+}
+
+func (r *rowReader) month(name string) int {
+	value := r.cell(name)
+	failed := len(r.errors)
+	n := r.integer(name)
+	if len(r.errors) == failed && value != "" && (n < 1 || n > 12) {
+		r.fail(name, value, "bulan harus 1-12")
+	}
+	return n
+}
+
+func (r *rowReader) timestamp(name string) time.Time {
+	value := r.cell(name)
+	if r.empty(name, value) {
+		return time.Time{}
+	}
+
+	t, err := ParseTimestamp(value)
+	if err != nil {
+		r.fail(name, value, "format tanggal tidak dikenali")
+	}
+	return t
+}
+
+// result mengembalikan status baris: error kalau ada kolom yang salah, selain itu accepted.
+func (r *rowReader) result(row int) models.ImportRowResult {
+	if len(r.errors) > 0 {
+		return models.ImportRowResult{Row: row, Status: models.ImportRowError, Errors: r.errors}
+	}
+	return models.ImportRowResult{Row: row, Status: models.ImportRowAccepted}
+}
+
+// ParseNumber membaca angka dari sel Excel, termasuk format "Rp 1.500.000",
+// "1,234,567.89" dan "12,5". Teks yang bukan angka dikembalikan sebagai error,
+// tidak dianggap 0.
+func ParseNumber(value string) (float64, error) {
+	return parseNumber(value, NumberFormatDot)
+}
+
+// NumberFormat menentukan arti titik dan koma tunggal di teks angka. Angka dengan titik
+// dan koma sekaligus (mis. "1.500.000,50") selalu dibaca dari pemisah yang terakhir.
+type NumberFormat string
+
+const (
+	// NumberFormatAuto: satu titik atau koma dengan tepat tiga digit di belakangnya adalah
+	// ribuan ("1.500" = "1,500" = 1500), selain itu desimal ("12.5" = "12,5" = 12.5).
+	NumberFormatAuto NumberFormat = ""
+	// NumberFormatDot: titik untuk desimal, koma untuk ribuan ("1.500" = 1.5).
+	NumberFormatDot NumberFormat = "dot"
+	// NumberFormatComma: format Indonesia, koma untuk desimal, titik untuk ribuan ("1,500" = 1.5).
+	NumberFormatComma NumberFormat = "comma"
+)
+
+// parseNumber sama dengan ParseNumber, dengan arti titik dan koma tunggal sesuai format.
+func parseNumber(value string, format NumberFormat) (float64, error) {
+	s := strings.TrimSpace(value)
+	if len(s) >= 2 && strings.EqualFold(s[:2], "rp") {
+		s = strings.TrimPrefix(s[2:], ".")
+	}
+	s = strings.NewReplacer(" ", "", "\u00a0", "").Replace(s)
+	if s == "" {
+		return 0, errEmptyNumber
+	}
+
+	for i, ch := range s {
+		if (ch < '0' || ch > '9') && ch != '.' && ch != ',' && !(ch == '-' && i == 0) {
+			return 0, fmt.Errorf("invalid number: %q", value)
+		}
+	}
+
+	dots := strings.Count(s, ".")
+	commas := strings.Count(s, ",")
+
+	switch {
+	case dots > 0 && commas > 0:
+		// Pemisah yang muncul terakhir adalah pemisah desimal.
+		if strings.LastIndex(s, ",") > strings.LastIndex(s, ".") {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case commas > 1:
+		s = strings.ReplaceAll(s, ",", "")
+	case commas == 1:
+		// "1,234" dianggap ribuan, "12,5" dianggap desimal.
+		if format != NumberFormatComma && len(s)-strings.Index(s, ",")-1 == 3 {
+			s = strings.Replace(s, ",", "", 1)
+		} else {
+			s = strings.Replace(s, ",", ".", 1)
+		}
+	case dots > 1:
+		s = strings.ReplaceAll(s, ".", "")
+	case dots == 1 && format != NumberFormatDot:
+		if len(s)-strings.Index(s, ".")-1 == 3 {
+			s = strings.Replace(s, ".", "", 1)
+		}
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number: %q", value)
+	}
+	return n, nil
+}
@@ -0,0 +1,277 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	templateDataSheet    = "Data"
+	templateGuideSheet   = "Petunjuk"
+	templateChoicesSheet = "Pilihan"
+
+	// templateRows adalah jumlah baris yang diberi format dan dropdown.
+	templateRows = 1000
+)
+
+// NewImportTemplate membuat file .xlsx kosong untuk import dengan header, format angka,
+// dropdown, dan satu baris contoh sesuai definisi kolom, plus sheet petunjuk. choices
+// berisi pilihan dropdown per nama kolom (mis. region yang sudah ada); pilihan ini hanya
+// saran, nilai lain tetap boleh diisi. Kolom bulan (1-12) selalu diberi dropdown.
+func NewImportTemplate(columns *ColumnSet, choices map[string][]string) (*excelize.File, error) {
+	f := excelize.NewFile()
+	if err := f.SetSheetName("Sheet1", templateDataSheet); err != nil {
+		return nil, err
+	}
+	if _, err := f.NewSheet(templateGuideSheet); err != nil {
+		return nil, err
+	}
+
+	headerStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#E0E0E0"}, Pattern: 1},
+	})
+	if err != nil {
+		return nil, err
+	}
+	requiredStyle, err := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#FFE699"}, Pattern: 1},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	choiceColumn := 0
+	for i, col := range columns.Columns() {
+		name, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		header := name + "1"
+		if err := f.SetCellValue(templateDataSheet, header, col.Name); err != nil {
+			return nil, err
+		}
+		style := headerStyle
+		if col.Required {
+			style = requiredStyle
+		}
+		if err := f.SetCellStyle(templateDataSheet, header, header, style); err != nil {
+			return nil, err
+		}
+		if err := f.SetColWidth(templateDataSheet, name, name, float64(max(len(col.Name), 12)+4)); err != nil {
+			return nil, err
+		}
+
+		// Format dipasang per sel, bukan per kolom, supaya header tetap memakai style-nya.
+		format, err := f.NewStyle(templateFormat(col.Type))
+		if err != nil {
+			return nil, err
+		}
+		dataRange := fmt.Sprintf("%s2:%s%d", name, name, templateRows+1)
+		if err := f.SetCellStyle(templateDataSheet, name+"2", fmt.Sprintf("%s%d", name, templateRows+1), format); err != nil {
+			return nil, err
+		}
+		if err := f.SetCellValue(templateDataSheet, name+"2", templateExample(col)); err != nil {
+			return nil, err
+		}
+
+		options := choices[col.Name]
+		if col.Type == ColumnMonth && len(options) == 0 {
+			for month := 1; month <= 12; month++ {
+				options = append(options, strconv.Itoa(month))
+			}
+		}
+		if len(options) > 0 {
+			// Pilihan ditulis ke sheet tersembunyi karena daftar langsung dibatasi 255 karakter.
+			choiceColumn++
+			if err := addChoices(f, choiceColumn, col, options, dataRange); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if err := addTypeValidation(f, col, dataRange); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := f.SetPanes(templateDataSheet, &excelize.Panes{
+		Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft",
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := writeTemplateGuide(f, columns, choiceColumn > 0, headerStyle); err != nil {
+		return nil, err
+	}
+
+	f.SetActiveSheet(0)
+	return f, nil
+}
+
+// addChoices menulis pilihan satu kolom ke sheet Pilihan dan memasang dropdown-nya.
+// Kolom bulan menolak nilai di luar daftar; kolom lain hanya memberi peringatan.
+func addChoices(f *excelize.File, index int, col Column, options []string, dataRange string) error {
+	if index == 1 {
+		if _, err := f.NewSheet(templateChoicesSheet); err != nil {
+			return err
+		}
+		if err := f.SetSheetVisible(templateChoicesSheet, false); err != nil {
+			return err
+		}
+	}
+
+	name, err := excelize.ColumnNumberToName(index)
+	if err != nil {
+		return err
+	}
+	if err := f.SetCellValue(templateChoicesSheet, name+"1", col.Name); err != nil {
+		return err
+	}
+	for i, option := range options {
+		var value interface{} = option
+		if col.Type == ColumnMonth {
+			if n, err := strconv.Atoi(option); err == nil {
+				value = n
+			}
+		}
+		if err := f.SetCellValue(templateChoicesSheet, fmt.Sprintf("%s%d", name, i+2), value); err != nil {
+			return err
+		}
+	}
+
+	dv := excelize.NewDataValidation(true)
+	dv.SetSqref(dataRange)
+	dv.SetSqrefDropList(fmt.Sprintf("'%s'!$%s$2:$%s$%d", templateChoicesSheet, name, name, len(options)+1))
+	if col.Type == ColumnMonth {
+		dv.SetError(excelize.DataValidationErrorStyleStop, col.Name, "Bulan harus 1-12")
+	} else {
+		dv.SetError(excelize.DataValidationErrorStyleWarning, col.Name, "Nilai ini belum ada di data. Lanjutkan?")
+	}
+	return f.AddDataValidation(templateDataSheet, dv)
+}
+
+// addTypeValidation memberi peringatan kalau kolom angka diisi teks. Kolom nilai tidak
+// divalidasi karena boleh berisi "80/100".
+func addTypeValidation(f *excelize.File, col Column, dataRange string) error {
+	dv := excelize.NewDataValidation(true)
+	dv.SetSqref(dataRange)
+	switch col.Type {
+	case ColumnNumber:
+		if err := dv.SetRange(-1e15, 1e15, excelize.DataValidationTypeDecimal, excelize.DataValidationOperatorBetween); err != nil {
+			return err
+		}
+		dv.SetError(excelize.DataValidationErrorStyleWarning, col.Name, "Kolom ini harus berisi angka")
+	case ColumnInteger:
+		if err := dv.SetRange(0, 9999, excelize.DataValidationTypeWhole, excelize.DataValidationOperatorBetween); err != nil {
+			return err
+		}
+		dv.SetError(excelize.DataValidationErrorStyleStop, col.Name, "Kolom ini harus berisi bilangan bulat")
+	default:
+		return nil
+	}
+	return f.AddDataValidation(templateDataSheet, dv)
+}
+
+// writeTemplateGuide menulis sheet Petunjuk berisi aturan umum dan penjelasan setiap kolom.
+func writeTemplateGuide(f *excelize.File, columns *ColumnSet, hasChoices bool, headerStyle int) error {
+	notes := []string{
+		"Petunjuk pengisian",
+		"Isi data di sheet Data mulai baris 2. Baris 2 adalah contoh: timpa atau hapus sebelum import.",
+		"Jangan mengubah nama header. Urutan kolom boleh diubah dan kolom tambahan akan diabaikan.",
+		"Header berwarna kuning adalah kolom wajib. Kolom angka yang kosong dianggap 0.",
+	}
+	if hasChoices {
+		notes = append(notes, "Dropdown berisi nilai yang sudah ada di data; nilai baru tetap boleh diisi kecuali kolom bulan.")
+	}
+
+	for i, note := range notes {
+		if err := f.SetCellValue(templateGuideSheet, fmt.Sprintf("A%d", i+1), note); err != nil {
+			return err
+		}
+	}
+	titleStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}})
+	if err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(templateGuideSheet, "A1", "A1", titleStyle); err != nil {
+		return err
+	}
+
+	start := len(notes) + 2
+	headers := []interface{}{"Kolom", "Wajib", "Format", "Nama header lain yang diterima", "Contoh"}
+	if err := f.SetSheetRow(templateGuideSheet, fmt.Sprintf("A%d", start), &headers); err != nil {
+		return err
+	}
+	if err := f.SetCellStyle(templateGuideSheet, fmt.Sprintf("A%d", start), fmt.Sprintf("E%d", start), headerStyle); err != nil {
+		return err
+	}
+
+	for i, col := range columns.Columns() {
+		required := "Tidak"
+		if col.Required {
+			required = "Ya"
+		}
+		row := []interface{}{col.Name, required, templateDescription(col.Type), strings.Join(col.Aliases, ", "), col.Example}
+		if err := f.SetSheetRow(templateGuideSheet, fmt.Sprintf("A%d", start+i+1), &row); err != nil {
+			return err
+		}
+	}
+
+	for col, width := range map[string]float64{"A": 28, "B": 8, "C": 60, "D": 40, "E": 22} {
+		if err := f.SetColWidth(templateGuideSheet, col, col, width); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// templateFormat mengembalikan format sel per jenis kolom. Format dipilih supaya teks
+// yang dibaca kembali oleh import tidak kehilangan angka atau berubah arti.
+func templateFormat(t ColumnType) *excelize.Style {
+	switch t {
+	case ColumnNumber, ColumnScore:
+		return &excelize.Style{NumFmt: 4} // #,##0.00
+	case ColumnInteger, ColumnMonth:
+		return &excelize.Style{NumFmt: 1} // 0
+	case ColumnTimestamp:
+		format := "yyyy-mm-dd hh:mm:ss"
+		return &excelize.Style{CustomNumFmt: &format}
+	}
+	// Teks, supaya nomor seperti "00123" tidak diubah menjadi angka.
+	return &excelize.Style{NumFmt: 49}
+}
+
+// templateExample mengubah contoh kolom menjadi nilai sel sesuai jenisnya.
+func templateExample(col Column) interface{} {
+	switch col.Type {
+	case ColumnNumber, ColumnScore, ColumnInteger, ColumnMonth:
+		if n, err := ParseNumber(col.Example); err == nil {
+			return n
+		}
+	case ColumnTimestamp:
+		if t, err := ParseTimestamp(col.Example); err == nil {
+			return t
+		}
+	}
+	return col.Example
+}
+
+func templateDescription(t ColumnType) string {
+	switch t {
+	case ColumnNumber:
+		return "Angka, boleh memakai pemisah ribuan, mis. 1.500.000 atau Rp 1.500.000"
+	case ColumnScore:
+		return "Angka, atau nilai seperti 80/100 (yang dibaca 80)"
+	case ColumnInteger:
+		return "Bilangan bulat"
+	case ColumnMonth:
+		return "Nomor bulan 1-12"
+	case ColumnTimestamp:
+		return "Tanggal dan jam, mis. 2025-01-17 14:47:50 atau 1/17/2025 14:47:50"
+	}
+	return "Teks"
+}
@@ -0,0 +1,468 @@
+package utils
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+)
+
+const (
+	pdfMargin       = 15.0
+	pdfContentWidth = 180.0 // lebar isi A4 (210mm) setelah margin kiri dan kanan
+	pdfBottomMargin = 18.0
+	pdfRowHeight    = 6.0
+)
+
+var monthNames = [...]string{"", "Januari", "Februari", "Maret", "April", "Mei", "Juni", "Juli", "Agustus", "September", "Oktober", "November", "Desember"}
+
+// MonthName mengembalikan nama bulan 1-12 dalam bahasa Indonesia.
+func MonthName(month int) string {
+	if month < 1 || month > 12 {
+		return strconv.Itoa(month)
+	}
+	return monthNames[month]
+}
+
+type pdfColumn struct {
+	title string
+	width float64
+	align string
+}
+
+type pdfSeries struct {
+	name   string
+	color  [3]int
+	values []float64
+}
+
+var (
+	pdfTargetColor  = [3]int{189, 189, 189}
+	pdfSelloutColor = [3]int{68, 114, 196}
+	pdfScoreColor   = [3]int{112, 173, 71}
+)
+
+// monthlyPDF menulis laporan bulanan dengan font bawaan PDF, jadi tidak butuh file font.
+type monthlyPDF struct {
+	pdf *fpdf.Fpdf
+	tr  func(string) string
+}
+
+// WriteMonthlyReportPDF menulis laporan bulanan satu cabang sebagai PDF A4 yang siap
+// dicetak: sellout vs target beserta grafik tren, top colorist, dan hasil tes Coloris
+// serta Training per materi.
+func WriteMonthlyReportPDF(w io.Writer, report *models.MonthlyReport) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfBottomMargin)
+	pdf.AliasNbPages("")
+	p := &monthlyPDF{pdf: pdf, tr: pdf.UnicodeTranslatorFromDescriptor("")}
+
+	period := fmt.Sprintf("%s %d", MonthName(report.Bulan), report.Tahun)
+	pdf.SetTitle(fmt.Sprintf("Laporan Bulanan %s - %s", report.Cabang, period), true)
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.SetX(pdfMargin)
+		pdf.CellFormat(pdfContentWidth, 5, p.tr(fmt.Sprintf("Laporan bulanan %s - %s", report.Cabang, period)), "", 0, "L", false, 0, "")
+		pdf.SetX(pdfMargin)
+		pdf.CellFormat(pdfContentWidth, 5, fmt.Sprintf("Halaman %d dari {nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+	p.header(report, period)
+	p.sellout(report)
+	p.topColorists(report.TopColorists)
+	p.tests("3. Hasil Tes Coloris", report.Coloris)
+	p.tests("4. Hasil Tes Training", report.Training)
+
+	if err := pdf.Error(); err != nil {
+		return err
+	}
+	return pdf.Output(w)
+}
+
+func (p *monthlyPDF) header(report *models.MonthlyReport, period string) {
+	pdf := p.pdf
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.CellFormat(pdfContentWidth, 8, p.tr("Laporan Bulanan Cabang "+report.Cabang), "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 10)
+	pdf.SetTextColor(80, 80, 80)
+	line := "Periode: " + period
+	if report.Region != "" {
+		line += "    Region: " + report.Region
+	}
+	pdf.CellFormat(pdfContentWidth, 5, p.tr(line), "", 1, "L", false, 0, "")
+	pdf.CellFormat(pdfContentWidth, 5, "Dibuat: "+report.GeneratedAt.Format("02/01/2006 15:04"), "", 1, "L", false, 0, "")
+
+	y := pdf.GetY() + 2
+	pdf.SetDrawColor(200, 200, 200)
+	pdf.Line(pdfMargin, y, pdfMargin+pdfContentWidth, y)
+	pdf.SetY(y + 4)
+}
+
+// section menulis judul bagian. Kalau sisa halaman kurang dari need (mm), judul dan isinya
+// dipindah ke halaman baru supaya tidak terpisah.
+func (p *monthlyPDF) section(title string, need float64) {
+	p.ensureSpace(need + 10)
+	pdf := p.pdf
+	pdf.SetFont("Helvetica", "B", 12)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.CellFormat(pdfContentWidth, 8, p.tr(title), "", 1, "L", false, 0, "")
+	pdf.Ln(1)
+}
+
+// ensureSpace menambah halaman kalau sisa halaman kurang dari height (mm).
+func (p *monthlyPDF) ensureSpace(height float64) bool {
+	_, pageHeight := p.pdf.GetPageSize()
+	if p.pdf.GetY()+height > pageHeight-pdfBottomMargin {
+		p.pdf.AddPage()
+		return true
+	}
+	return false
+}
+
+func (p *monthlyPDF) sellout(report *models.MonthlyReport) {
+	pdf := p.pdf
+	p.section("1. Sellout vs Target", 95)
+
+	total := report.Sellout
+	achievement, ok := total.Achievement()
+	achievementText := "-"
+	if ok {
+		achievementText = formatPercent(achievement)
+	}
+	boxes := []struct {
+		label, value string
+		fill         [3]int
+	}{
+		{"Target Sellout", formatNumber(total.TargetSellout), [3]int{242, 242, 242}},
+		{"Total Sellout", formatNumber(total.TotalSellout), [3]int{242, 242, 242}},
+		{"Pencapaian", achievementText, achievementFill(achievement, ok)},
+		{"Colorist / Outlet", fmt.Sprintf("%d / %d", total.Colorists, total.Outlets), [3]int{242, 242, 242}},
+	}
+	const gap = 4.0
+	width := (pdfContentWidth - gap*float64(len(boxes)-1)) / float64(len(boxes))
+	y := pdf.GetY()
+	for i, box := range boxes {
+		x := pdfMargin + float64(i)*(width+gap)
+		pdf.SetFillColor(box.fill[0], box.fill[1], box.fill[2])
+		pdf.Rect(x, y, width, 18, "F")
+		pdf.SetXY(x+3, y+2)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.SetTextColor(90, 90, 90)
+		pdf.CellFormat(width-6, 4, box.label, "", 0, "L", false, 0, "")
+		pdf.SetXY(x+3, y+8)
+		pdf.SetFont("Helvetica", "B", 13)
+		pdf.SetTextColor(0, 0, 0)
+		pdf.CellFormat(width-6, 7, box.value, "", 0, "L", false, 0, "")
+	}
+	pdf.SetXY(pdfMargin, y+21)
+
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(60, 60, 60)
+	composition := fmt.Sprintf("Sellout TT: %s    Sellout RM: %s    Primafix: %s",
+		formatNumber(total.SelloutTT), formatNumber(total.SelloutRM), formatNumber(total.Primafix))
+	pdf.CellFormat(pdfContentWidth, 5, composition, "", 1, "L", false, 0, "")
+	pdf.Ln(3)
+
+	pdf.SetFont("Helvetica", "B", 10)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.CellFormat(pdfContentWidth, 6, fmt.Sprintf("Tren %d bulan terakhir", len(report.Trend)), "", 1, "L", false, 0, "")
+
+	labels := make([]string, len(report.Trend))
+	target := pdfSeries{name: "Target", color: pdfTargetColor, values: make([]float64, len(report.Trend))}
+	sellout := pdfSeries{name: "Total Sellout", color: pdfSelloutColor, values: make([]float64, len(report.Trend))}
+	for i, month := range report.Trend {
+		labels[i] = fmt.Sprintf("%s %02d", MonthName(month.Bulan)[:3], month.Tahun%100)
+		target.values[i] = month.TargetSellout
+		sellout.values[i] = month.TotalSellout
+	}
+	p.barChart(labels, []pdfSeries{target, sellout}, 50)
+}
+
+func (p *monthlyPDF) topColorists(colorists []models.SelloutSummary) {
+	p.section("2. Top Colorist", 30)
+	if len(colorists) == 0 {
+		p.empty()
+		return
+	}
+
+	columns := []pdfColumn{
+		{"No", 8, "C"},
+		{"Nama Colorist", 46, "L"},
+		{"No Reg", 26, "L"},
+		{"Target", 26, "R"},
+		{"Total Sellout", 26, "R"},
+		{"Pencapaian", 22, "R"},
+		{"", 26, "L"},
+	}
+	highest := 0.0
+	for _, colorist := range colorists {
+		highest = math.Max(highest, colorist.TotalSellout)
+	}
+
+	p.tableHeader(columns)
+	for i, colorist := range colorists {
+		achievement := "-"
+		if value, ok := colorist.Achievement(); ok {
+			achievement = formatPercent(value)
+		}
+		p.tableRow(columns, []string{
+			strconv.Itoa(i + 1),
+			colorist.NamaColorist,
+			colorist.NoReg,
+			formatNumber(colorist.TargetSellout),
+			formatNumber(colorist.TotalSellout),
+			achievement,
+		}, ratio(colorist.TotalSellout, highest), pdfSelloutColor)
+	}
+	p.pdf.Ln(4)
+}
+
+func (p *monthlyPDF) tests(title string, summary []models.TestSummary) {
+	p.section(title, 30)
+	if len(summary) == 0 {
+		p.empty()
+		return
+	}
+
+	columns := []pdfColumn{
+		{"Materi", 60, "L"},
+		{"Peserta", 18, "R"},
+		{"Rata-rata", 22, "R"},
+		{"Tertinggi", 22, "R"},
+		{"Terendah", 22, "R"},
+		{"", 36, "L"},
+	}
+	highest := 0.0
+	participants := 0
+	for _, row := range summary {
+		highest = math.Max(highest, row.Highest)
+		participants += row.Participants
+	}
+
+	p.tableHeader(columns)
+	for _, row := range summary {
+		materi := row.Materi
+		if strings.TrimSpace(materi) == "" {
+			materi = "(tanpa materi)"
+		}
+		p.tableRow(columns, []string{
+			materi,
+			strconv.Itoa(row.Participants),
+			formatDecimal(row.Average),
+			formatDecimal(row.Highest),
+			formatDecimal(row.Lowest),
+		}, ratio(row.Average, highest), pdfScoreColor)
+	}
+
+	p.pdf.SetFont("Helvetica", "", 8)
+	p.pdf.SetTextColor(110, 110, 110)
+	p.pdf.CellFormat(pdfContentWidth, 5, fmt.Sprintf("Total %d peserta. Grafik menunjukkan rata-rata nilai dibanding nilai tertinggi.", participants), "", 1, "L", false, 0, "")
+	p.pdf.Ln(4)
+}
+
+func (p *monthlyPDF) empty() {
+	p.pdf.SetFont("Helvetica", "I", 9)
+	p.pdf.SetTextColor(110, 110, 110)
+	p.pdf.CellFormat(pdfContentWidth, 6, "Tidak ada data untuk periode ini.", "", 1, "L", false, 0, "")
+	p.pdf.Ln(4)
+}
+
+func (p *monthlyPDF) tableHeader(columns []pdfColumn) {
+	pdf := p.pdf
+	pdf.SetFont("Helvetica", "B", 9)
+	pdf.SetFillColor(224, 224, 224)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetX(pdfMargin)
+	for _, col := range columns {
+		pdf.CellFormat(col.width, pdfRowHeight+1, col.title, "", 0, col.align, true, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+// tableRow menulis satu baris tabel. Kolom terakhir tanpa nilai diisi batang sepanjang bar
+// (0-1) sebagai grafik batang sederhana.
+func (p *monthlyPDF) tableRow(columns []pdfColumn, values []string, bar float64, color [3]int) {
+	pdf := p.pdf
+	if p.ensureSpace(pdfRowHeight) {
+		p.tableHeader(columns)
+	}
+
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetDrawColor(225, 225, 225)
+	pdf.SetX(pdfMargin)
+	y := pdf.GetY()
+	for i, col := range columns {
+		if i >= len(values) {
+			x := pdf.GetX()
+			if bar > 0 {
+				pdf.SetFillColor(color[0], color[1], color[2])
+				pdf.Rect(x+1, y+1.5, (col.width-2)*bar, pdfRowHeight-3, "F")
+			}
+			pdf.SetX(x + col.width)
+			continue
+		}
+		pdf.CellFormat(col.width, pdfRowHeight, p.fit(values[i], col.width-2), "B", 0, col.align, false, 0, "")
+	}
+	pdf.Ln(-1)
+}
+
+// barChart menggambar grafik batang vertikal sederhana setinggi height (mm): satu batang
+// per seri untuk setiap label, sumbu Y mulai dari 0, dengan garis bantu dan legenda.
+func (p *monthlyPDF) barChart(labels []string, series []pdfSeries, height float64) {
+	pdf := p.pdf
+	if len(labels) == 0 {
+		p.empty()
+		return
+	}
+	p.ensureSpace(height + 16)
+
+	const axisWidth = 18.0
+	top := pdf.GetY() + 6
+	left := pdfMargin + axisWidth
+	width := pdfContentWidth - axisWidth
+
+	// Legenda di kanan atas.
+	pdf.SetFont("Helvetica", "", 8)
+	legendX := pdfMargin + pdfContentWidth
+	for i := len(series) - 1; i >= 0; i-- {
+		s := series[i]
+		legendX -= pdf.GetStringWidth(s.name) + 8
+		pdf.SetFillColor(s.color[0], s.color[1], s.color[2])
+		pdf.Rect(legendX, top-4.5, 3, 3, "F")
+		pdf.SetXY(legendX+4, top-5)
+		pdf.SetTextColor(60, 60, 60)
+		pdf.CellFormat(pdf.GetStringWidth(s.name)+2, 4, s.name, "", 0, "L", false, 0, "")
+	}
+
+	highest := 0.0
+	for _, s := range series {
+		for _, value := range s.values {
+			highest = math.Max(highest, value)
+		}
+	}
+	step := niceStep(highest / 4)
+	axisMax := step * 4
+
+	pdf.SetDrawColor(225, 225, 225)
+	pdf.SetTextColor(110, 110, 110)
+	for i := 0; i <= 4; i++ {
+		y := top + height - float64(i)*height/4
+		pdf.Line(left, y, left+width, y)
+		pdf.SetXY(pdfMargin, y-2)
+		pdf.CellFormat(axisWidth-2, 4, compactNumber(step*float64(i)), "", 0, "R", false, 0, "")
+	}
+
+	groupWidth := width / float64(len(labels))
+	barWidth := groupWidth * 0.7 / float64(len(series))
+	for i, label := range labels {
+		groupX := left + float64(i)*groupWidth
+		for j, s := range series {
+			barHeight := s.values[i] / axisMax * height
+			if barHeight <= 0 {
+				continue
+			}
+			pdf.SetFillColor(s.color[0], s.color[1], s.color[2])
+			pdf.Rect(groupX+groupWidth*0.15+float64(j)*barWidth, top+height-barHeight, barWidth, barHeight, "F")
+		}
+		pdf.SetXY(groupX, top+height+1)
+		pdf.CellFormat(groupWidth, 4, label, "", 0, "C", false, 0, "")
+	}
+	pdf.SetXY(pdfMargin, top+height+8)
+}
+
+// fit memotong teks supaya muat di lebar width (mm) dengan font yang sedang dipakai.
+func (p *monthlyPDF) fit(text string, width float64) string {
+	text = p.tr(text)
+	if p.pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	for len(text) > 0 && p.pdf.GetStringWidth(text+"...") > width {
+		text = text[:len(text)-1]
+	}
+	return text + "..."
+}
+
+// achievementFill mengembalikan warna kotak pencapaian dengan batas yang sama seperti laporan Excel.
+func achievementFill(value float64, ok bool) [3]int {
+	switch {
+	case !ok:
+		return [3]int{242, 242, 242}
+	case value >= reportAchievementGood:
+		return [3]int{198, 239, 206}
+	case value >= reportAchievementWarn:
+		return [3]int{255, 235, 156}
+	}
+	return [3]int{255, 199, 206}
+}
+
+func ratio(value, highest float64) float64 {
+	if highest <= 0 || value <= 0 {
+		return 0
+	}
+	return math.Min(value/highest, 1)
+}
+
+// niceStep membulatkan jarak garis bantu ke 1, 2, 2.5, atau 5 kali pangkat sepuluh.
+func niceStep(value float64) float64 {
+	if value <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(value)))
+	for _, factor := range []float64{1, 2, 2.5, 5, 10} {
+		if value <= factor*magnitude {
+			return factor * magnitude
+		}
+	}
+	return 10 * magnitude
+}
+
+// formatNumber menulis angka bulat dengan pemisah ribuan titik, mis. 1.500.000.
+func formatNumber(value float64) string {
+	digits := strconv.FormatInt(int64(math.Round(math.Abs(value))), 10)
+	var b strings.Builder
+	if value <= -0.5 {
+		b.WriteByte('-')
+	}
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte('.')
+		}
+		b.WriteRune(digit)
+	}
+	return b.String()
+}
+
+// formatDecimal menulis angka dengan satu desimal memakai koma, mis. 85,5.
+func formatDecimal(value float64) string {
+	s := strconv.FormatFloat(value, 'f', 1, 64)
+	s = strings.TrimSuffix(s, ".0")
+	return strings.Replace(s, ".", ",", 1)
+}
+
+func formatPercent(value float64) string {
+	return formatDecimal(value*100) + "%"
+}
+
+// compactNumber menyingkat angka untuk label sumbu, mis. 1,5 jt atau 250 rb.
+func compactNumber(value float64) string {
+	switch {
+	case value >= 1e9:
+		return formatDecimal(value/1e9) + " M"
+	case value >= 1e6:
+		return formatDecimal(value/1e6) + " jt"
+	case value >= 1e3:
+		return formatDecimal(value/1e3) + " rb"
+	}
+	return formatDecimal(value)
+}
@@ -0,0 +1,396 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/web-dashboard-made-by-renz/backend/internal/models"
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	reportSummarySheet = "Ringkasan"
+	reportDataSheet    = "Data"
+
+	// Batas warna pencapaian: hijau kalau target tercapai, kuning kalau minimal 80%.
+	reportAchievementGood = 1.0
+	reportAchievementWarn = 0.8
+)
+
+// SelloutReport adalah isi sheet Ringkasan laporan sellout.
+type SelloutReport struct {
+	// Description menjelaskan filter yang dipakai, mis. "tahun 2025, region Jakarta".
+	Description string
+	Total       models.SelloutSummary
+	Regions     []models.SelloutSummary
+	Cabangs     []models.SelloutSummary
+}
+
+// reportSheet menyimpan style yang dipakai berulang saat menulis sheet Ringkasan.
+type reportSheet struct {
+	f       *excelize.File
+	header  int
+	number  int
+	percent int
+	label   int
+	total   int
+	totalNo int
+	totalPc int
+	good    int
+	warn    int
+	bad     int
+}
+
+// reportTable adalah satu tabel ringkasan; keys mengisi kolom pengelompokan di kiri.
+type reportTable struct {
+	title string
+	keys  []string
+	rows  []models.SelloutSummary
+	key   func(models.SelloutSummary) []interface{}
+	total *models.SelloutSummary
+}
+
+var reportValueHeaders = []string{"Colorist", "Outlet", "Target Sellout", "Sellout TT", "Sellout RM", "Primafix", "Total Sellout", "Pencapaian"}
+
+// NewSelloutReport membuat workbook laporan sellout. Sheet Ringkasan berisi total
+// keseluruhan, tabel per region dan per cabang dengan pencapaian terhadap target yang
+// diberi warna, serta grafik. Data mentah ditulis ke sheet Data lewat ExcelWriter yang
+// dikembalikan, sama seperti export biasa.
+func NewSelloutReport(report SelloutReport, columns *ExportColumns) (*ExcelWriter, error) {
+	f := excelize.NewFile()
+	w, err := writeSelloutReport(f, report, columns)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+func writeSelloutReport(f *excelize.File, report SelloutReport, columns *ExportColumns) (*ExcelWriter, error) {
+	if err := f.SetSheetName("Sheet1", reportSummarySheet); err != nil {
+		return nil, err
+	}
+	sheet, err := newReportSheet(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := sheet.writeOverview(report); err != nil {
+		return nil, err
+	}
+
+	row := 11
+	regions := reportTable{
+		title: "Per Region",
+		keys:  []string{"Region"},
+		rows:  report.Regions,
+		key:   func(s models.SelloutSummary) []interface{} { return []interface{}{s.Reg} },
+		total: &report.Total,
+	}
+	regionFirst, regionLast, next, err := sheet.writeTable(row, regions)
+	if err != nil {
+		return nil, err
+	}
+	cabangs := reportTable{
+		title: "Per Cabang",
+		keys:  []string{"Region", "Cabang"},
+		rows:  report.Cabangs,
+		key:   func(s models.SelloutSummary) []interface{} { return []interface{}{s.Reg, s.Cabang} },
+	}
+	cabangFirst, cabangLast, _, err := sheet.writeTable(next+2, cabangs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(report.Regions) > 0 {
+		if err := sheet.addRegionChart(regionFirst, regionLast); err != nil {
+			return nil, err
+		}
+	}
+	if len(report.Cabangs) > 0 {
+		if err := sheet.addCabangChart(cabangFirst, cabangLast); err != nil {
+			return nil, err
+		}
+	}
+
+	for col, width := range map[string]float64{"A": 22, "B": 22, "C": 12, "D": 12, "E": 18, "F": 18, "G": 18, "H": 18, "I": 18, "J": 14} {
+		if err := f.SetColWidth(reportSummarySheet, col, col, width); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := f.NewSheet(reportDataSheet); err != nil {
+		return nil, err
+	}
+	f.SetActiveSheet(0)
+	return newExcelWriter(f, reportDataSheet, columns)
+}
+
+func newReportSheet(f *excelize.File) (*reportSheet, error) {
+	s := &reportSheet{f: f}
+	border := []excelize.Border{{Type: "bottom", Color: "#808080", Style: 1}}
+	topBorder := []excelize.Border{{Type: "top", Color: "#000000", Style: 1}}
+	styles := []struct {
+		id    *int
+		style *excelize.Style
+	}{
+		{&s.header, &excelize.Style{
+			Font:      &excelize.Font{Bold: true},
+			Fill:      excelize.Fill{Type: "pattern", Color: []string{"#E0E0E0"}, Pattern: 1},
+			Border:    border,
+			Alignment: &excelize.Alignment{Horizontal: "center", WrapText: true},
+		}},
+		{&s.number, &excelize.Style{NumFmt: 3}},   // #,##0
+		{&s.percent, &excelize.Style{NumFmt: 10}}, // 0.00%
+		{&s.label, &excelize.Style{Font: &excelize.Font{Bold: true}}},
+		{&s.total, &excelize.Style{Font: &excelize.Font{Bold: true}, Border: topBorder}},
+		{&s.totalNo, &excelize.Style{Font: &excelize.Font{Bold: true}, Border: topBorder, NumFmt: 3}},
+		{&s.totalPc, &excelize.Style{Font: &excelize.Font{Bold: true}, Border: topBorder, NumFmt: 10}},
+	}
+	for _, item := range styles {
+		id, err := f.NewStyle(item.style)
+		if err != nil {
+			return nil, err
+		}
+		*item.id = id
+	}
+
+	conditional := []struct {
+		id         *int
+		font, fill string
+	}{
+		{&s.good, "#006100", "#C6EFCE"},
+		{&s.warn, "#9C5700", "#FFEB9C"},
+		{&s.bad, "#9C0006", "#FFC7CE"},
+	}
+	for _, item := range conditional {
+		id, err := f.NewConditionalStyle(&excelize.Style{
+			Font: &excelize.Font{Color: item.font},
+			Fill: excelize.Fill{Type: "pattern", Color: []string{item.fill}, Pattern: 1},
+		})
+		if err != nil {
+			return nil, err
+		}
+		*item.id = id
+	}
+	return s, nil
+}
+
+// writeOverview menulis judul, filter, dan angka total di bagian atas sheet.
+func (s *reportSheet) writeOverview(report SelloutReport) error {
+	f := s.f
+	titleStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true, Size: 14}})
+	if err != nil {
+		return err
+	}
+	description := report.Description
+	if description == "" {
+		description = "Semua data"
+	}
+	cells := map[string]interface{}{
+		"A1": "Laporan Sellout",
+		"A2": "Filter: " + description,
+		"A3": "Dibuat: " + time.Now().Format("2006-01-02 15:04"),
+	}
+	for cell, value := range cells {
+		if err := f.SetCellValue(reportSummarySheet, cell, value); err != nil {
+			return err
+		}
+	}
+	if err := f.SetCellStyle(reportSummarySheet, "A1", "A1", titleStyle); err != nil {
+		return err
+	}
+
+	total := report.Total
+	achievement, ok := total.Achievement()
+	figures := []struct {
+		label string
+		value interface{}
+		style int
+	}{
+		{"Target Sellout", total.TargetSellout, s.number},
+		{"Total Sellout", total.TotalSellout, s.number},
+		{"Pencapaian", reportPercent(achievement, ok), s.percent},
+		{"Jumlah Colorist", total.Colorists, s.number},
+		{"Jumlah Outlet", total.Outlets, s.number},
+	}
+	for i, figure := range figures {
+		row := 5 + i
+		if err := f.SetCellValue(reportSummarySheet, fmt.Sprintf("A%d", row), figure.label); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(reportSummarySheet, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), s.label); err != nil {
+			return err
+		}
+		if err := f.SetCellValue(reportSummarySheet, fmt.Sprintf("B%d", row), figure.value); err != nil {
+			return err
+		}
+		if err := f.SetCellStyle(reportSummarySheet, fmt.Sprintf("B%d", row), fmt.Sprintf("B%d", row), figure.style); err != nil {
+			return err
+		}
+	}
+	if err := s.highlightAchievement("B7", "B7"); err != nil {
+		return err
+	}
+	note := fmt.Sprintf("Pencapaian = Total Sellout / Target Sellout. Hijau >= %.0f%%, kuning >= %.0f%%, merah di bawahnya; kosong kalau target tidak diisi.",
+		reportAchievementGood*100, reportAchievementWarn*100)
+	return f.SetCellValue(reportSummarySheet, "C7", note)
+}
+
+// writeTable menulis satu tabel mulai dari baris start (judul), lalu header dan datanya.
+// Mengembalikan baris data pertama dan terakhir serta baris terakhir yang terpakai.
+func (s *reportSheet) writeTable(start int, table reportTable) (first, last, end int, err error) {
+	f := s.f
+	if err := f.SetCellValue(reportSummarySheet, fmt.Sprintf("A%d", start), table.title); err != nil {
+		return 0, 0, 0, err
+	}
+	if err := f.SetCellStyle(reportSummarySheet, fmt.Sprintf("A%d", start), fmt.Sprintf("A%d", start), s.label); err != nil {
+		return 0, 0, 0, err
+	}
+
+	headers := make([]interface{}, 0, len(table.keys)+len(reportValueHeaders))
+	for _, key := range table.keys {
+		headers = append(headers, key)
+	}
+	for _, header := range reportValueHeaders {
+		headers = append(headers, header)
+	}
+	lastColumn, err := excelize.ColumnNumberToName(len(headers))
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	headerRow := start + 1
+	if err := f.SetSheetRow(reportSummarySheet, fmt.Sprintf("A%d", headerRow), &headers); err != nil {
+		return 0, 0, 0, err
+	}
+	if err := f.SetCellStyle(reportSummarySheet, fmt.Sprintf("A%d", headerRow), fmt.Sprintf("%s%d", lastColumn, headerRow), s.header); err != nil {
+		return 0, 0, 0, err
+	}
+
+	first = headerRow + 1
+	row := headerRow
+	if len(table.rows) == 0 {
+		row++
+		if err := f.SetCellValue(reportSummarySheet, fmt.Sprintf("A%d", row), "Tidak ada data"); err != nil {
+			return 0, 0, 0, err
+		}
+		return first, first, row, nil
+	}
+
+	firstValue, err := excelize.ColumnNumberToName(len(table.keys) + 1)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	achievementColumn := lastColumn
+	for _, summary := range table.rows {
+		row++
+		if err := s.writeSummaryRow(row, table.key(summary), summary); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	last = row
+	if err := f.SetCellStyle(reportSummarySheet, fmt.Sprintf("%s%d", firstValue, first), fmt.Sprintf("%s%d", lastColumn, last), s.number); err != nil {
+		return 0, 0, 0, err
+	}
+	if err := f.SetCellStyle(reportSummarySheet, fmt.Sprintf("%s%d", achievementColumn, first), fmt.Sprintf("%s%d", achievementColumn, last), s.percent); err != nil {
+		return 0, 0, 0, err
+	}
+	if err := s.highlightAchievement(fmt.Sprintf("%s%d", achievementColumn, first), fmt.Sprintf("%s%d", achievementColumn, last)); err != nil {
+		return 0, 0, 0, err
+	}
+
+	if table.total != nil {
+		row++
+		keys := make([]interface{}, len(table.keys))
+		keys[0] = "Total"
+		if err := s.writeSummaryRow(row, keys, *table.total); err != nil {
+			return 0, 0, 0, err
+		}
+		if err := f.SetCellStyle(reportSummarySheet, fmt.Sprintf("A%d", row), fmt.Sprintf("%s%d", lastColumn, row), s.totalNo); err != nil {
+			return 0, 0, 0, err
+		}
+		if err := f.SetCellStyle(reportSummarySheet, fmt.Sprintf("A%d", row), fmt.Sprintf("A%d", row), s.total); err != nil {
+			return 0, 0, 0, err
+		}
+		if err := f.SetCellStyle(reportSummarySheet, fmt.Sprintf("%s%d", achievementColumn, row), fmt.Sprintf("%s%d", achievementColumn, row), s.totalPc); err != nil {
+			return 0, 0, 0, err
+		}
+		if err := s.highlightAchievement(fmt.Sprintf("%s%d", achievementColumn, row), fmt.Sprintf("%s%d", achievementColumn, row)); err != nil {
+			return 0, 0, 0, err
+		}
+	}
+	return first, last, row, nil
+}
+
+func (s *reportSheet) writeSummaryRow(row int, keys []interface{}, summary models.SelloutSummary) error {
+	achievement, ok := summary.Achievement()
+	values := append(append([]interface{}{}, keys...),
+		summary.Colorists,
+		summary.Outlets,
+		summary.TargetSellout,
+		summary.SelloutTT,
+		summary.SelloutRM,
+		summary.Primafix,
+		summary.TotalSellout,
+		reportPercent(achievement, ok),
+	)
+	return s.f.SetSheetRow(reportSummarySheet, fmt.Sprintf("A%d", row), &values)
+}
+
+// highlightAchievement mewarnai sel pencapaian. Rumus memakai ISNUMBER supaya sel kosong
+// (target tidak diisi) tidak ikut dianggap 0% dan berwarna merah.
+func (s *reportSheet) highlightAchievement(from, to string) error {
+	good := fmt.Sprintf("%g", reportAchievementGood)
+	warn := fmt.Sprintf("%g", reportAchievementWarn)
+	return s.f.SetConditionalFormat(reportSummarySheet, from+":"+to, []excelize.ConditionalFormatOptions{
+		{Type: "formula", Criteria: fmt.Sprintf("AND(ISNUMBER(%s),%s>=%s)", from, from, good), Format: &s.good, StopIfTrue: true},
+		{Type: "formula", Criteria: fmt.Sprintf("AND(ISNUMBER(%s),%s>=%s)", from, from, warn), Format: &s.warn, StopIfTrue: true},
+		{Type: "formula", Criteria: fmt.Sprintf("ISNUMBER(%s)", from), Format: &s.bad},
+	})
+}
+
+// addRegionChart menambahkan grafik kolom target vs total sellout per region.
+func (s *reportSheet) addRegionChart(first, last int) error {
+	ref := func(col string) string {
+		return fmt.Sprintf("%s!$%s$%d:$%s$%d", reportSummarySheet, col, first, col, last)
+	}
+	return s.f.AddChart(reportSummarySheet, "L2", &excelize.Chart{
+		Type: excelize.Col,
+		Series: []excelize.ChartSeries{
+			{Name: fmt.Sprintf("%s!$D$%d", reportSummarySheet, first-1), Categories: ref("A"), Values: ref("D")},
+			{Name: fmt.Sprintf("%s!$H$%d", reportSummarySheet, first-1), Categories: ref("A"), Values: ref("H")},
+		},
+		Title:     []excelize.RichTextRun{{Text: "Target vs Total Sellout per Region"}},
+		Legend:    excelize.ChartLegend{Position: "bottom"},
+		YAxis:     excelize.ChartAxis{MajorGridLines: true, NumFmt: excelize.ChartNumFmt{CustomNumFmt: "#,##0"}},
+		Dimension: excelize.ChartDimension{Width: 640, Height: 320},
+	})
+}
+
+// addCabangChart menambahkan grafik batang pencapaian per cabang. Tinggi grafik mengikuti
+// jumlah cabang supaya labelnya tetap terbaca.
+func (s *reportSheet) addCabangChart(first, last int) error {
+	ref := func(col string) string {
+		return fmt.Sprintf("%s!$%s$%d:$%s$%d", reportSummarySheet, col, first, col, last)
+	}
+	height := uint(max(320, 24*(last-first+1)+120))
+	return s.f.AddChart(reportSummarySheet, "L20", &excelize.Chart{
+		Type: excelize.Bar,
+		Series: []excelize.ChartSeries{
+			{Name: fmt.Sprintf("%s!$J$%d", reportSummarySheet, first-1), Categories: ref("B"), Values: ref("J")},
+		},
+		Title:     []excelize.RichTextRun{{Text: "Pencapaian per Cabang"}},
+		Legend:    excelize.ChartLegend{Position: "none"},
+		XAxis:     excelize.ChartAxis{ReverseOrder: true},
+		YAxis:     excelize.ChartAxis{MajorGridLines: true, NumFmt: excelize.ChartNumFmt{CustomNumFmt: "0%"}},
+		Dimension: excelize.ChartDimension{Width: 640, Height: height},
+	})
+}
+
+// reportPercent mengembalikan nilai pencapaian, atau nil (sel kosong) kalau target tidak diisi.
+func reportPercent(value float64, ok bool) interface{} {
+	if !ok {
+		return nil
+	}
+	return value
+}
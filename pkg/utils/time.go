@@ -15,6 +15,7 @@ func ParseTimestamp(dateStr string) (time.Time, error) {
 		"01/02/2006",
 		"2/1/2006 15:04:05",
 		"02/01/2006 15:04:05",
+		"1/2/06 15:04",
 		time.RFC3339,
 	}
 
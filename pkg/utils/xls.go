@@ -0,0 +1,629 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/richardlehane/mscfb"
+)
+
+// Record BIFF8 yang dibaca. Record lain (format tampilan, style, dsb.) dilewati.
+const (
+	xlsRecordFormula    = 0x0006
+	xlsRecordEOF        = 0x000A
+	xlsRecordDateMode   = 0x0022
+	xlsRecordFilePass   = 0x002F
+	xlsRecordContinue   = 0x003C
+	xlsRecordBoundSheet = 0x0085
+	xlsRecordMulRK      = 0x00BD
+	xlsRecordXF         = 0x00E0
+	xlsRecordSST        = 0x00FC
+	xlsRecordLabelSST   = 0x00FD
+	xlsRecordNumber     = 0x0203
+	xlsRecordLabel      = 0x0204
+	xlsRecordBoolErr    = 0x0205
+	xlsRecordString     = 0x0207
+	xlsRecordRK         = 0x027E
+	xlsRecordFormat     = 0x041E
+	xlsRecordBOF        = 0x0809
+
+	xlsBIFF8 = 0x0600
+
+	// Batas ukuran sheet BIFF8.
+	xlsMaxRows    = 65536
+	xlsMaxColumns = 256
+)
+
+var (
+	errXLSTruncated  = errors.New("truncated .xls record")
+	errXLSCellBounds = fmt.Errorf("cell outside the %d x %d sheet limit", xlsMaxRows, xlsMaxColumns)
+)
+
+// xlsRecord adalah satu record BIFF beserta record CONTINUE yang mengikutinya.
+type xlsRecord struct {
+	kind     uint16
+	segments [][]byte
+}
+
+func (r xlsRecord) data() []byte {
+	return r.segments[0]
+}
+
+type xlsSheet struct {
+	name   string
+	offset uint32
+}
+
+// xlsWorkbook adalah workbook Excel 97-2003 (BIFF8) yang sudah dibaca dari container OLE.
+type xlsWorkbook struct {
+	stream  []byte
+	sheets  []xlsSheet
+	strings []string
+	// xfFormats adalah kode format angka per XF (style sel), untuk mengenali tanggal.
+	xfFormats []string
+	date1904  bool
+}
+
+// openXLS membaca stream Workbook dari file .xls. Excel 5.0/95 dan workbook yang
+// diproteksi password tidak didukung.
+func openXLS(data []byte) (*xlsWorkbook, error) {
+	doc, err := mscfb.New(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnreadableFile, err)
+	}
+
+	var stream []byte
+	for entry, err := doc.Next(); err == nil; entry, err = doc.Next() {
+		switch entry.Name {
+		case "Workbook":
+			// Ukuran stream dari directory tidak bisa dipercaya; stream tidak mungkin lebih
+			// besar dari file-nya sendiri.
+			if entry.Size < 0 || entry.Size > int64(len(data)) {
+				return nil, fmt.Errorf("%w: workbook stream size %d exceeds file size", ErrUnreadableFile, entry.Size)
+			}
+			stream = make([]byte, entry.Size)
+			if _, err := io.ReadFull(entry, stream); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrUnreadableFile, err)
+			}
+		case "Book":
+			return nil, &UnsupportedFormatError{Format: "Excel 5.0/95 (.xls)"}
+		case "EncryptedPackage":
+			return nil, &UnsupportedFormatError{Format: "password-protected workbook"}
+		}
+	}
+	if stream == nil {
+		return nil, &UnsupportedFormatError{Format: "OLE document without a workbook"}
+	}
+
+	wb := &xlsWorkbook{stream: stream}
+	if err := wb.readGlobals(); err != nil {
+		return nil, err
+	}
+	return wb, nil
+}
+
+// readRecords membaca record mulai dari offset sampai record EOF substream tersebut.
+func (wb *xlsWorkbook) readRecords(offset uint32, handle func(xlsRecord) error) error {
+	pos := int(offset)
+	var current *xlsRecord
+	flush := func() error {
+		if current == nil {
+			return nil
+		}
+		record := *current
+		current = nil
+		return handle(record)
+	}
+
+	for {
+		if pos+4 > len(wb.stream) {
+			return fmt.Errorf("%w: %v", ErrUnreadableFile, errXLSTruncated)
+		}
+		kind := binary.LittleEndian.Uint16(wb.stream[pos:])
+		size := int(binary.LittleEndian.Uint16(wb.stream[pos+2:]))
+		pos += 4
+		if pos+size > len(wb.stream) {
+			return fmt.Errorf("%w: %v", ErrUnreadableFile, errXLSTruncated)
+		}
+		body := wb.stream[pos : pos+size]
+		pos += size
+
+		if kind == xlsRecordContinue && current != nil {
+			current.segments = append(current.segments, body)
+			continue
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		if kind == xlsRecordEOF {
+			return nil
+		}
+		current = &xlsRecord{kind: kind, segments: [][]byte{body}}
+	}
+}
+
+func (wb *xlsWorkbook) readGlobals() error {
+	formats := map[uint16]string{}
+	var xfFormatIDs []uint16
+
+	err := wb.readRecords(0, func(record xlsRecord) error {
+		data := record.data()
+		switch record.kind {
+		case xlsRecordBOF:
+			if len(data) < 2 || binary.LittleEndian.Uint16(data) != xlsBIFF8 {
+				return &UnsupportedFormatError{Format: "Excel 5.0/95 (.xls)"}
+			}
+		case xlsRecordFilePass:
+			return &UnsupportedFormatError{Format: "password-protected workbook"}
+		case xlsRecordDateMode:
+			wb.date1904 = len(data) >= 2 && binary.LittleEndian.Uint16(data) == 1
+		case xlsRecordFormat:
+			if len(data) < 2 {
+				return errXLSTruncated
+			}
+			r := newXLSReader(record.segments)
+			id, _ := r.u16()
+			code, err := r.unicodeString(2)
+			if err != nil {
+				return err
+			}
+			formats[id] = code
+		case xlsRecordXF:
+			if len(data) < 4 {
+				return errXLSTruncated
+			}
+			xfFormatIDs = append(xfFormatIDs, binary.LittleEndian.Uint16(data[2:]))
+		case xlsRecordBoundSheet:
+			// Hanya worksheet biasa; chart sheet dan macro sheet dilewati.
+			if len(data) < 8 || data[5] != 0 {
+				return nil
+			}
+			r := newXLSReader([][]byte{data[6:]})
+			name, err := r.unicodeString(1)
+			if err != nil {
+				return err
+			}
+			wb.sheets = append(wb.sheets, xlsSheet{name: name, offset: binary.LittleEndian.Uint32(data)})
+		case xlsRecordSST:
+			r := newXLSReader(record.segments)
+			if err := r.skip(4); err != nil {
+				return err
+			}
+			count, err := r.u32()
+			if err != nil {
+				return err
+			}
+			// count berasal dari file; setiap string minimal 3 byte, jadi kapasitas awal
+			// dibatasi sisa data record supaya count palsu tidak memesan memori besar.
+			wb.strings = make([]string, 0, min(int64(count), int64(r.remaining()/3)))
+			for i := uint32(0); i < count; i++ {
+				s, err := r.richString()
+				if err != nil {
+					return err
+				}
+				wb.strings = append(wb.strings, s)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrUnsupportedFormat) || errors.Is(err, ErrUnreadableFile) {
+			return err
+		}
+		return fmt.Errorf("%w: %v", ErrUnreadableFile, err)
+	}
+
+	wb.xfFormats = make([]string, len(xfFormatIDs))
+	for i, id := range xfFormatIDs {
+		if code, ok := formats[id]; ok {
+			wb.xfFormats[i] = code
+		} else {
+			wb.xfFormats[i] = xlsBuiltinFormats[id]
+		}
+	}
+	return nil
+}
+
+// SheetNames mengembalikan nama worksheet sesuai urutan di workbook.
+func (wb *xlsWorkbook) SheetNames() []string {
+	names := make([]string, len(wb.sheets))
+	for i, sheet := range wb.sheets {
+		names[i] = sheet.name
+	}
+	return names
+}
+
+// Rows membaca isi satu sheet sebagai teks. Angka bertipe tanggal diubah menjadi
+// "2006-01-02 15:04:05" supaya bisa dibaca ParseTimestamp.
+func (wb *xlsWorkbook) Rows(index int) ([][]string, error) {
+	if index < 0 || index >= len(wb.sheets) {
+		return nil, fmt.Errorf("sheet %d not found", index)
+	}
+
+	var rows [][]string
+	set := func(row, col int, value string) error {
+		if row >= xlsMaxRows || col >= xlsMaxColumns {
+			return errXLSCellBounds
+		}
+		for len(rows) <= row {
+			rows = append(rows, nil)
+		}
+		for len(rows[row]) <= col {
+			rows[row] = append(rows[row], "")
+		}
+		rows[row][col] = value
+		return nil
+	}
+	setCell := func(data []byte, value string) error {
+		row, col := xlsCell(data)
+		return set(row, col, value)
+	}
+
+	// Hasil formula teks ada di record STRING setelah record FORMULA-nya.
+	var pendingRow, pendingCol int
+	pending := false
+
+	err := wb.readRecords(wb.sheets[index].offset, func(record xlsRecord) error {
+		data := record.data()
+		switch record.kind {
+		case xlsRecordLabelSST:
+			if len(data) < 10 {
+				return errXLSTruncated
+			}
+			i := binary.LittleEndian.Uint32(data[6:])
+			if int(i) < len(wb.strings) {
+				return setCell(data, wb.strings[i])
+			}
+		case xlsRecordLabel:
+			r := newXLSReader(record.segments)
+			if err := r.skip(6); err != nil {
+				return err
+			}
+			s, err := r.unicodeString(2)
+			if err != nil {
+				return err
+			}
+			return setCell(data, s)
+		case xlsRecordNumber:
+			if len(data) < 14 {
+				return errXLSTruncated
+			}
+			value := math.Float64frombits(binary.LittleEndian.Uint64(data[6:]))
+			return setCell(data, wb.formatNumber(value, binary.LittleEndian.Uint16(data[4:])))
+		case xlsRecordRK:
+			if len(data) < 10 {
+				return errXLSTruncated
+			}
+			value := decodeRK(binary.LittleEndian.Uint32(data[6:]))
+			return setCell(data, wb.formatNumber(value, binary.LittleEndian.Uint16(data[4:])))
+		case xlsRecordMulRK:
+			if len(data) < 6 {
+				return errXLSTruncated
+			}
+			row, col := xlsCell(data)
+			for pos := 4; pos+6 <= len(data)-2; pos += 6 {
+				xf := binary.LittleEndian.Uint16(data[pos:])
+				value := decodeRK(binary.LittleEndian.Uint32(data[pos+2:]))
+				if err := set(row, col, wb.formatNumber(value, xf)); err != nil {
+					return err
+				}
+				col++
+			}
+		case xlsRecordFormula:
+			if len(data) < 14 {
+				return errXLSTruncated
+			}
+			row, col := xlsCell(data)
+			result := data[6:14]
+			if result[6] != 0xFF || result[7] != 0xFF {
+				value := math.Float64frombits(binary.LittleEndian.Uint64(result))
+				return set(row, col, wb.formatNumber(value, binary.LittleEndian.Uint16(data[4:])))
+			}
+			switch result[0] {
+			case 0:
+				pendingRow, pendingCol, pending = row, col, true
+			case 1:
+				return set(row, col, xlsBool(result[2]))
+			}
+		case xlsRecordString:
+			if !pending {
+				return nil
+			}
+			pending = false
+			s, err := newXLSReader(record.segments).unicodeString(2)
+			if err != nil {
+				return err
+			}
+			return set(pendingRow, pendingCol, s)
+		case xlsRecordBoolErr:
+			if len(data) < 8 {
+				return errXLSTruncated
+			}
+			// Sel error (#N/A, #DIV/0!, dsb.) dibiarkan kosong.
+			if data[7] == 0 {
+				return setCell(data, xlsBool(data[6]))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrUnreadableFile) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrUnreadableFile, err)
+	}
+
+	return rows, nil
+}
+
+func xlsCell(data []byte) (row, col int) {
+	return int(binary.LittleEndian.Uint16(data)), int(binary.LittleEndian.Uint16(data[2:]))
+}
+
+func xlsBool(b byte) string {
+	if b != 0 {
+		return "TRUE"
+	}
+	return "FALSE"
+}
+
+// decodeRK membaca angka RK: bilangan bulat 30-bit atau 30 bit teratas float64,
+// opsional dibagi 100.
+func decodeRK(rk uint32) float64 {
+	var value float64
+	if rk&0x02 != 0 {
+		value = float64(int32(rk) >> 2)
+	} else {
+		value = math.Float64frombits(uint64(rk&0xFFFFFFFC) << 32)
+	}
+	if rk&0x01 != 0 {
+		value /= 100
+	}
+	return value
+}
+
+func (wb *xlsWorkbook) formatNumber(value float64, xf uint16) string {
+	if int(xf) < len(wb.xfFormats) && isDateFormat(wb.xfFormats[xf]) {
+		if t, ok := excelSerialToTime(value, wb.date1904); ok {
+			return t.Format("2006-01-02 15:04:05")
+		}
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// excelSerialToTime mengubah nomor seri tanggal Excel menjadi waktu, dibulatkan ke detik.
+func excelSerialToTime(serial float64, date1904 bool) (time.Time, bool) {
+	if serial < 0 || serial > 2958465 {
+		return time.Time{}, false
+	}
+
+	base := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	} else if serial < 61 {
+		// Excel menganggap 1900 tahun kabisat, jadi seri sebelum 1 Maret 1900 bergeser satu hari.
+		base = base.AddDate(0, 0, 1)
+	}
+
+	seconds := math.Round(serial * 86400)
+	return base.Add(time.Duration(seconds) * time.Second), true
+}
+
+// isDateFormat mengenali kode format angka yang menampilkan tanggal atau jam, dengan
+// mengabaikan teks dalam kutip, karakter escape, dan bagian dalam kurung siku.
+func isDateFormat(code string) bool {
+	if code == "" || strings.EqualFold(code, "General") {
+		return false
+	}
+
+	quoted, bracket, escaped := false, false, false
+	for _, ch := range code {
+		switch {
+		case escaped:
+			escaped = false
+		case quoted:
+			quoted = ch != '"'
+		case bracket:
+			bracket = ch != ']'
+		case ch == '\\':
+			escaped = true
+		case ch == '"':
+			quoted = true
+		case ch == '[':
+			bracket = true
+		case strings.ContainsRune("dmyhsDMYHS", ch):
+			return true
+		}
+	}
+	return false
+}
+
+// xlsBuiltinFormats adalah format bawaan Excel yang bertipe tanggal/jam. Format angka
+// bawaan lain tidak perlu dikenali.
+var xlsBuiltinFormats = map[uint16]string{
+	14: "m/d/yyyy",
+	15: "d-mmm-yy",
+	16: "d-mmm",
+	17: "mmm-yy",
+	18: "h:mm AM/PM",
+	19: "h:mm:ss AM/PM",
+	20: "h:mm",
+	21: "h:mm:ss",
+	22: "m/d/yyyy h:mm",
+	45: "mm:ss",
+	46: "[h]:mm:ss",
+	47: "mm:ss.0",
+}
+
+// xlsReader membaca data record yang terpotong ke beberapa record CONTINUE. Teks yang
+// terpotong di tengah diawali lagi dengan byte flag di segmen berikutnya.
+type xlsReader struct {
+	segments [][]byte
+	segment  int
+	pos      int
+}
+
+func newXLSReader(segments [][]byte) *xlsReader {
+	return &xlsReader{segments: segments}
+}
+
+// next memastikan masih ada byte di segmen sekarang dan mengembalikan true kalau
+// pembacaan baru saja pindah ke segmen berikutnya.
+func (r *xlsReader) next() (bool, error) {
+	moved := false
+	for r.segment < len(r.segments) && r.pos >= len(r.segments[r.segment]) {
+		r.segment++
+		r.pos = 0
+		moved = true
+	}
+	if r.segment >= len(r.segments) {
+		return moved, errXLSTruncated
+	}
+	return moved, nil
+}
+
+func (r *xlsReader) u8() (byte, error) {
+	if _, err := r.next(); err != nil {
+		return 0, err
+	}
+	b := r.segments[r.segment][r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *xlsReader) u16() (uint16, error) {
+	lo, err := r.u8()
+	if err != nil {
+		return 0, err
+	}
+	hi, err := r.u8()
+	return uint16(lo) | uint16(hi)<<8, err
+}
+
+func (r *xlsReader) u32() (uint32, error) {
+	lo, err := r.u16()
+	if err != nil {
+		return 0, err
+	}
+	hi, err := r.u16()
+	return uint32(lo) | uint32(hi)<<16, err
+}
+
+// remaining mengembalikan jumlah byte yang belum dibaca di semua segmen.
+func (r *xlsReader) remaining() int {
+	n := 0
+	for i := r.segment; i < len(r.segments); i++ {
+		n += len(r.segments[i])
+	}
+	return max(n-r.pos, 0)
+}
+
+func (r *xlsReader) skip(n int) error {
+	for n > 0 {
+		if _, err := r.next(); err != nil {
+			return err
+		}
+		step := min(n, len(r.segments[r.segment])-r.pos)
+		r.pos += step
+		n -= step
+	}
+	return nil
+}
+
+// unicodeString membaca XLUnicodeString dengan panjang lenSize byte (1 atau 2).
+func (r *xlsReader) unicodeString(lenSize int) (string, error) {
+	var length uint16
+	var err error
+	if lenSize == 1 {
+		var b byte
+		b, err = r.u8()
+		length = uint16(b)
+	} else {
+		length, err = r.u16()
+	}
+	if err != nil {
+		return "", err
+	}
+
+	flags, err := r.u8()
+	if err != nil {
+		return "", err
+	}
+	return r.chars(int(length), flags&0x01 != 0)
+}
+
+// richString membaca satu string di SST, termasuk format rich text dan data fonetik
+// yang dilewati.
+func (r *xlsReader) richString() (string, error) {
+	length, err := r.u16()
+	if err != nil {
+		return "", err
+	}
+	flags, err := r.u8()
+	if err != nil {
+		return "", err
+	}
+
+	var runs uint16
+	var extSize uint32
+	if flags&0x08 != 0 {
+		if runs, err = r.u16(); err != nil {
+			return "", err
+		}
+	}
+	if flags&0x04 != 0 {
+		if extSize, err = r.u32(); err != nil {
+			return "", err
+		}
+	}
+
+	s, err := r.chars(int(length), flags&0x01 != 0)
+	if err != nil {
+		return "", err
+	}
+	if err := r.skip(int(runs)*4 + int(extSize)); err != nil {
+		return "", err
+	}
+	return s, nil
+}
+
+// chars membaca n karakter, 1 byte (Latin-1) atau 2 byte (UTF-16LE) per karakter.
+func (r *xlsReader) chars(n int, wide bool) (string, error) {
+	units := make([]uint16, 0, n)
+	for len(units) < n {
+		moved, err := r.next()
+		if err != nil {
+			return "", err
+		}
+		if moved {
+			// Di awal record CONTINUE ada byte flag baru untuk sisa karakternya.
+			flags := r.segments[r.segment][r.pos]
+			r.pos++
+			wide = flags&0x01 != 0
+			continue
+		}
+
+		if wide {
+			unit, err := r.u16()
+			if err != nil {
+				return "", err
+			}
+			units = append(units, unit)
+		} else {
+			b, err := r.u8()
+			if err != nil {
+				return "", err
+			}
+			units = append(units, uint16(b))
+		}
+	}
+	return string(utf16.Decode(units)), nil
+}